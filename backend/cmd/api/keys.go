@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/work-near-me/backend/config"
+	"github.com/work-near-me/backend/pkg"
+)
+
+const keysUsage = "usage: api keys generate [EdDSA|RS256] [--if-empty]"
+
+// runKeys implements the `keys` subcommand, which manages the access token
+// signing keys in JWT_KEYS_DIR.
+func runKeys(cfg config.JWTConfig, args []string) error {
+	if len(args) == 0 || args[0] != "generate" {
+		return errors.New(keysUsage)
+	}
+	if cfg.KeysDir == "" {
+		return errors.New("JWT_KEYS_DIR is not set")
+	}
+
+	alg := "EdDSA"
+	ifEmpty := false
+	for _, arg := range args[1:] {
+		switch arg {
+		case "--if-empty":
+			ifEmpty = true
+		case "EdDSA", "RS256":
+			alg = arg
+		default:
+			return errors.New(keysUsage)
+		}
+	}
+
+	if ifEmpty {
+		existing, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			fmt.Printf("%s already holds %d key(s)\n", cfg.KeysDir, len(existing))
+			return nil
+		}
+	}
+
+	path, err := pkg.GenerateKeyFile(cfg.KeysDir, alg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s key %s\n", alg, path)
+	return nil
+}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -12,18 +18,33 @@ import (
 
 	"github.com/work-near-me/backend/config"
 	"github.com/work-near-me/backend/internal/delivery/http"
-	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/migrate"
 	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/internal/scheduler"
 	"github.com/work-near-me/backend/internal/usecase"
+	"github.com/work-near-me/backend/migrations"
+	"github.com/work-near-me/backend/pkg"
+	"github.com/work-near-me/backend/pkg/notify"
+	"github.com/work-near-me/backend/pkg/sms"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeys(cfg.JWT, os.Args[2:]); err != nil {
+			log.Fatalf("Keys: %v", err)
+		}
+		return
+	}
+
 	// Set Gin mode
 	gin.SetMode(cfg.Server.GinMode)
 
@@ -34,19 +55,37 @@ func main() {
 	}
 	log.Println("Connected to PostgreSQL")
 
-	// Auto-migrate
-	if err := db.AutoMigrate(
-		&domain.User{},
-		&domain.Job{},
-		&domain.Application{},
-		&domain.Rating{},
-	); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// Migrations
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
 	}
-	log.Println("Database migrated")
 
-	// Create location index
-	db.Exec("CREATE INDEX IF NOT EXISTS idx_jobs_location ON jobs(latitude, longitude)")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(migrator, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if err := migrator.Check(); err != nil {
+		log.Fatalf("Refusing to start: %v (run `api migrate up`)", err)
+	}
+	log.Println("Database schema is up to date")
+
+	if len(os.Args) > 1 && os.Args[1] == "users" {
+		if err := runUsers(repository.NewUnitOfWork(db), os.Args[2:]); err != nil {
+			log.Fatalf("Users: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "phones" {
+		if err := runPhones(repository.NewUnitOfWork(db), os.Args[2:]); err != nil {
+			log.Fatalf("Phones: %v", err)
+		}
+		return
+	}
 
 	// Connect to Redis
 	rdb := redis.NewClient(&redis.Options{
@@ -55,8 +94,8 @@ func main() {
 		DB:       cfg.Redis.DB,
 	})
 
-	if err := rdb.Ping(context.Background()).Err(); err != nil {
-		log.Printf("Warning: Redis connection failed: %v (rate limiting will be unavailable)", err)
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Printf("Warning: Redis connection failed: %v (rate limiting and phone verification will be unavailable)", err)
 	} else {
 		log.Println("Connected to Redis")
 	}
@@ -64,29 +103,205 @@ func main() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	jobRepo := repository.NewJobRepository(db)
+	if jobRepo.UsesGeography() {
+		log.Println("Nearby search: PostGIS geography index")
+	} else {
+		log.Println("Nearby search: PostGIS unavailable, using bounding box + Haversine")
+	}
 	appRepo := repository.NewApplicationRepository(db)
 	ratingRepo := repository.NewRatingRepository(db)
+	tokenRepo := repository.NewRefreshTokenRepository(db)
+	eventRepo := repository.NewAuthEventRepository(db)
+	recoveryRepo := repository.NewRecoveryCodeRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	reminderRepo := repository.NewRatingReminderRepository(db)
+	otpRepo := repository.NewOTPRepository(rdb)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(rdb)
+	denylistRepo := repository.NewDenylistRepository(rdb)
+	uow := repository.NewUnitOfWork(db)
+
+	// Access token signing keys
+	var keyring *pkg.Keyring
+	if cfg.JWT.KeysDir != "" {
+		keyring, err = pkg.LoadKeyring(cfg.JWT.KeysDir, cfg.JWT.KeyActivationDelay)
+		if err != nil {
+			log.Fatalf("Failed to load signing keys: %v", err)
+		}
+		go keyring.Watch(ctx, cfg.JWT.KeyReloadInterval)
+	} else {
+		if gin.Mode() == gin.ReleaseMode {
+			log.Fatal("JWT_KEYS_DIR must be set in release mode (create a key with `api keys generate`)")
+		}
+		keyring, err = pkg.NewEphemeralKeyring()
+		if err != nil {
+			log.Fatalf("Failed to generate signing key: %v", err)
+		}
+		log.Println("Warning: JWT_KEYS_DIR is not set, signing access tokens with an ephemeral key")
+	}
+	log.Printf("Signing access tokens with key %s", keyring.SigningKeyID())
+
+	// Refresh token and two-factor challenge keys. A key derived from
+	// JWT_SECRET is only as strong as it, so release mode needs real ones.
+	if cfg.JWT.RefreshSecretDerived {
+		if gin.Mode() == gin.ReleaseMode {
+			log.Fatal("JWT_REFRESH_SECRET must be set in release mode")
+		}
+		log.Println("Warning: JWT_REFRESH_SECRET is not set, deriving it from JWT_SECRET")
+	}
+	if cfg.JWT.MFASecretDerived {
+		if gin.Mode() == gin.ReleaseMode {
+			log.Fatal("JWT_MFA_SECRET must be set in release mode")
+		}
+		log.Println("Warning: JWT_MFA_SECRET is not set, deriving it from JWT_SECRET")
+	}
+
+	tokens := pkg.NewTokenIssuer(
+		cfg.JWT.Issuer,
+		keyring,
+		pkg.SigningKey{ID: cfg.JWT.RefreshKeyID, Secret: []byte(cfg.JWT.RefreshSecret)},
+		pkg.SigningKey{ID: cfg.JWT.MFAKeyID, Secret: []byte(cfg.JWT.MFASecret)},
+	)
+
+	// Two-factor secret encryption
+	var totpCipher *pkg.Cipher
+	if cfg.TOTP.EncryptionKey != "" {
+		totpCipher, err = pkg.NewCipher(cfg.TOTP.EncryptionKey)
+	} else {
+		if gin.Mode() == gin.ReleaseMode {
+			log.Fatal("TOTP_ENCRYPTION_KEY must be set in release mode")
+		}
+		totpCipher, err = pkg.NewEphemeralCipher()
+		log.Println("Warning: TOTP_ENCRYPTION_KEY is not set, two-factor enrollments will not survive a restart")
+	}
+	if err != nil {
+		log.Fatalf("Failed to set up two-factor secret encryption: %v", err)
+	}
+
+	// SMS delivery
+	var smsSender sms.Sender
+	switch cfg.SMS.Provider {
+	case "log":
+		smsSender = sms.NewLogSender()
+	case "file":
+		smsSender = sms.NewFileSender(cfg.SMS.FilePath)
+	default:
+		log.Fatalf("Unknown SMS_PROVIDER %q", cfg.SMS.Provider)
+	}
+
+	// Notifications
+	var notifier notify.Notifier
+	switch cfg.Notify.Provider {
+	case "sms":
+		notifier = notify.NewSMSNotifier(smsSender)
+	case "log":
+		notifier = notify.NewLogNotifier()
+	default:
+		log.Fatalf("Unknown NOTIFY_PROVIDER %q", cfg.Notify.Provider)
+	}
 
 	// Initialize use cases
-	authUC := usecase.NewAuthUseCase(userRepo, cfg)
-	jobUC := usecase.NewJobUseCase(jobRepo, ratingRepo, cfg)
-	appUC := usecase.NewApplicationUseCase(appRepo, jobRepo)
-	ratingUC := usecase.NewRatingUseCase(ratingRepo, userRepo, jobRepo)
+	otp := usecase.NewOTPService(otpRepo, smsSender, cfg.OTP)
+	phones := usecase.NewPhoneVerificationPolicy(userRepo, cfg.App.RequirePhoneVerification)
+	guard := usecase.NewLoginGuard(loginAttemptRepo, smsSender, cfg.Login)
+	twoFactorUC := usecase.NewTwoFactorUseCase(userRepo, recoveryRepo, eventRepo, uow, totpCipher, guard, cfg.TOTP)
+	sessionUC := usecase.NewSessionUseCase(sessionRepo, denylistRepo, uow, cfg)
+	authUC := usecase.NewAuthUseCase(userRepo, tokenRepo, eventRepo, uow, tokens, otp, guard, twoFactorUC, sessionUC, cfg)
+	jobUC := usecase.NewJobUseCase(jobRepo, ratingRepo, uow, phones, cfg)
+	appUC := usecase.NewApplicationUseCase(appRepo, jobRepo, uow, phones)
+	userUC := usecase.NewUserUseCase(userRepo, jobRepo)
+	ratingUC := usecase.NewRatingUseCase(ratingRepo, userRepo, jobRepo, reminderRepo, uow, notifier, cfg.Rating)
+	adminUC := usecase.NewAdminUseCase(userRepo, jobRepo, appRepo, ratingRepo, auditRepo, uow, sessionUC)
 
 	// Initialize handlers
 	authH := http.NewAuthHandler(authUC)
+	twoFactorH := http.NewTwoFactorHandler(twoFactorUC)
+	sessionH := http.NewSessionHandler(sessionUC)
+	userH := http.NewUserHandler(userUC)
 	jobH := http.NewJobHandler(jobUC)
 	appH := http.NewApplicationHandler(appUC)
 	ratingH := http.NewRatingHandler(ratingUC)
+	adminH := http.NewAdminHandler(adminUC)
 
 	// Setup router
-	router := http.NewRouter(authH, jobH, appH, ratingH, cfg.JWT.Secret, rdb)
+	router := http.NewRouter(authH, twoFactorH, sessionH, userH, jobH, appH, ratingH, adminH, tokens, denylistRepo, rdb)
 	engine := router.Setup()
 
+	// Start background tasks
+	var sched *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		sched = scheduler.New(scheduler.NewLeaderElector(rdb, "scheduler:leader", cfg.Scheduler.LeaderTTL))
+		sched.Register(scheduler.Task{
+			Name:     "expire-jobs",
+			Interval: cfg.Scheduler.JobExpiryInterval,
+			Run: func(ctx context.Context) error {
+				n, err := jobUC.ExpireStale()
+				if n > 0 {
+					log.Printf("Expired %d stale jobs", n)
+				}
+				return err
+			},
+		})
+		sched.Register(scheduler.Task{
+			Name:     "purge-refresh-tokens",
+			Interval: cfg.Scheduler.TokenCleanupInterval,
+			Run: func(ctx context.Context) error {
+				_, err := authUC.PurgeExpiredTokens()
+				return err
+			},
+		})
+		sched.Register(scheduler.Task{
+			Name:     "purge-sessions",
+			Interval: cfg.Scheduler.TokenCleanupInterval,
+			Run: func(ctx context.Context) error {
+				_, err := sessionUC.PurgeExpired()
+				return err
+			},
+		})
+		sched.Register(scheduler.Task{
+			Name:     "reveal-ratings",
+			Interval: cfg.Scheduler.RatingRevealInterval,
+			Run: func(ctx context.Context) error {
+				n, err := ratingUC.RevealDue()
+				if n > 0 {
+					log.Printf("Revealed %d ratings past the reveal window", n)
+				}
+				return err
+			},
+		})
+		sched.Register(scheduler.Task{
+			Name:     "rating-reminders",
+			Interval: cfg.Scheduler.RatingReminderInterval,
+			Run: func(ctx context.Context) error {
+				n, err := ratingUC.SendReminders()
+				if n > 0 {
+					log.Printf("Sent %d rating reminders", n)
+				}
+				return err
+			},
+		})
+		sched.Start(ctx)
+	}
+
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
-	log.Printf("Server starting on %s", addr)
-	if err := engine.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &nethttp.Server{Addr: addr, Handler: engine}
+	go func() {
+		log.Printf("Server starting on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
+	}
+	if sched != nil {
+		sched.Wait()
 	}
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/work-near-me/backend/internal/migrate"
+)
+
+const migrateUsage = "usage: api migrate <up|down|status|to VERSION>"
+
+// runMigrate implements the `migrate` subcommand.
+func runMigrate(m *migrate.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		ran, err := m.Up()
+		printMigrations("Applied", ran)
+		return err
+
+	case "down":
+		reverted, err := m.Down()
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			fmt.Println("No migrations to revert")
+			return nil
+		}
+		fmt.Printf("Reverted %d_%s\n", reverted.Version, reverted.Name)
+		return nil
+
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		ran, err := m.To(version)
+		printMigrations("Ran", ran)
+		return err
+
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	}
+
+	return errors.New(migrateUsage)
+}
+
+func printMigrations(verb string, ran []migrate.Migration) {
+	if len(ran) == 0 {
+		fmt.Println("Schema is up to date")
+		return
+	}
+	for _, mig := range ran {
+		fmt.Printf("%s %d_%s\n", verb, mig.Version, mig.Name)
+	}
+}
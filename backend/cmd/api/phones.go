@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg"
+)
+
+const phonesUsage = "usage: api phones normalize [--dry-run]"
+
+// runPhones implements the `phones` subcommand. `phones normalize` rewrites
+// stored phone numbers to E.164, as registration has done since phone
+// normalization was introduced. Rows whose numbers normalize to the same
+// value as another row, and numbers that cannot be normalized, are left
+// untouched and reported for manual resolution.
+func runPhones(uow *repository.UnitOfWork, args []string) error {
+	if len(args) == 0 || args[0] != "normalize" {
+		return errors.New(phonesUsage)
+	}
+	dryRun := false
+	for _, arg := range args[1:] {
+		if arg != "--dry-run" {
+			return errors.New(phonesUsage)
+		}
+		dryRun = true
+	}
+
+	var updated, collisions, invalid int
+	err := uow.Do(func(repos *repository.Repositories) error {
+		users, err := repos.Users.ListPhones()
+		if err != nil {
+			return err
+		}
+
+		groups := map[string][]domain.User{}
+		var order []string
+		for _, user := range users {
+			normalized, err := pkg.NormalizePhone(user.Phone)
+			if err != nil {
+				fmt.Printf("INVALID    %s  %q\n", user.ID, user.Phone)
+				invalid++
+				continue
+			}
+			if _, seen := groups[normalized]; !seen {
+				order = append(order, normalized)
+			}
+			groups[normalized] = append(groups[normalized], user)
+		}
+
+		for _, normalized := range order {
+			group := groups[normalized]
+			if len(group) > 1 {
+				fmt.Printf("COLLISION  %s\n", normalized)
+				for _, user := range group {
+					fmt.Printf("           %s  %q  registered %s\n", user.ID, user.Phone, user.CreatedAt.Format("2006-01-02"))
+				}
+				collisions++
+				continue
+			}
+
+			user := group[0]
+			if user.Phone == normalized {
+				continue
+			}
+			fmt.Printf("UPDATE     %s  %q -> %s\n", user.ID, user.Phone, normalized)
+			updated++
+			if !dryRun {
+				if err := repos.Users.UpdatePhone(user.ID, normalized); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Updated"
+	if dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("%s %d phone numbers; %d collisions and %d invalid numbers left unchanged\n", verb, updated, collisions, invalid)
+	if collisions > 0 || invalid > 0 {
+		return errors.New("some phone numbers need manual resolution")
+	}
+	return nil
+}
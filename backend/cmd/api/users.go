@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg"
+)
+
+const usersUsage = "usage: api users <grant-admin|revoke-admin> PHONE"
+
+// runUsers implements the `users` subcommand. The admin role cannot be
+// obtained through the API, so operators grant and revoke it here. Both are
+// recorded in the audit log without an acting admin.
+func runUsers(uow *repository.UnitOfWork, args []string) error {
+	if len(args) != 2 || (args[0] != "grant-admin" && args[0] != "revoke-admin") {
+		return errors.New(usersUsage)
+	}
+	grant := args[0] == "grant-admin"
+
+	phone, err := pkg.NormalizePhone(args[1])
+	if err != nil {
+		return err
+	}
+
+	return uow.Do(func(repos *repository.Repositories) error {
+		user, err := repos.Users.FindByPhone(phone)
+		if err != nil {
+			return fmt.Errorf("no user with phone number %s", phone)
+		}
+
+		action := domain.AuditActionAdminGranted
+		if grant {
+			if user.Roles.Has(domain.RoleAdmin) {
+				return fmt.Errorf("%s is already an admin", phone)
+			}
+			user.Roles = append(user.Roles, domain.RoleAdmin)
+		} else {
+			if !user.Roles.Has(domain.RoleAdmin) {
+				return fmt.Errorf("%s is not an admin", phone)
+			}
+			if err := revokeAdmin(repos, user); err != nil {
+				return err
+			}
+			action = domain.AuditActionAdminRevoked
+		}
+
+		if err := repos.Users.UpdateRoles(user); err != nil {
+			return err
+		}
+		if err := repos.AuditLogs.Create(&domain.AuditLog{
+			Action:     action,
+			TargetType: domain.AuditTargetUser,
+			TargetID:   user.ID,
+		}); err != nil {
+			return err
+		}
+
+		if grant {
+			fmt.Printf("Granted admin to %s (%s); they can switch to it with PUT /api/auth/role\n", user.Name, phone)
+		} else {
+			fmt.Printf("Revoked admin from %s (%s); access tokens already issued expire within JWT_ACCESS_EXPIRY\n", user.Name, phone)
+		}
+		return nil
+	})
+}
+
+// revokeAdmin removes the admin role from user, switching the active role
+// to a remaining one, and revokes the user's sessions so no refresh can
+// issue another admin token.
+func revokeAdmin(repos *repository.Repositories, user *domain.User) error {
+	var remaining domain.UserRoles
+	for _, role := range user.Roles {
+		if role != domain.RoleAdmin {
+			remaining = append(remaining, role)
+		}
+	}
+	if len(remaining) == 0 {
+		return errors.New("admin is the user's only role; it cannot be revoked")
+	}
+
+	user.Roles = remaining
+	if user.Role == domain.RoleAdmin {
+		user.Role = remaining[0]
+	}
+
+	if _, err := repos.Sessions.RevokeAllForUser(user.ID); err != nil {
+		return err
+	}
+	return repos.RefreshTokens.RevokeAllForUser(user.ID)
+}
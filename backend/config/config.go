@@ -1,17 +1,28 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	App      AppConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	App       AppConfig
+	Login     LoginConfig
+	TOTP      TOTPConfig
+	OTP       OTPConfig
+	Rating    RatingConfig
+	Notify    NotifyConfig
+	SMS       SMSConfig
+	Scheduler SchedulerConfig
 }
 
 type ServerConfig struct {
@@ -36,13 +47,104 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret        string
-	AccessExpiry  time.Duration
-	RefreshExpiry time.Duration
+	Issuer             string
+	KeysDir            string
+	KeyActivationDelay time.Duration
+	KeyReloadInterval  time.Duration
+	RefreshSecret      string
+	RefreshKeyID       string
+	MFASecret          string
+	MFAKeyID           string
+	AccessExpiry       time.Duration
+	RefreshExpiry      time.Duration
+	MFAExpiry          time.Duration
+
+	// RefreshSecretDerived and MFASecretDerived are set when
+	// JWT_REFRESH_SECRET or JWT_MFA_SECRET is unset and the secret was
+	// derived from JWT_SECRET instead.
+	RefreshSecretDerived bool
+	MFASecretDerived     bool
 }
 
 type AppConfig struct {
 	MaxSearchRadiusKM float64
+	DefaultTimezone   string
+	OpenJobTTL        time.Duration
+	// OpenJobStartGrace is how long an open job may stay open past its start
+	// time before it expires.
+	OpenJobStartGrace time.Duration
+	// RequirePhoneVerification blocks users with an unverified phone number
+	// from posting jobs and applying.
+	RequirePhoneVerification bool
+}
+
+// LoginConfig controls the per-phone lockout after failed logins. The first
+// FreeAttempts failures are free; every further failure locks the number for
+// LockoutBase, doubling each time up to LockoutMax. Failures are forgotten
+// after a successful login or FailureWindow without failures.
+type LoginConfig struct {
+	FreeAttempts  int
+	LockoutBase   time.Duration
+	LockoutMax    time.Duration
+	FailureWindow time.Duration
+}
+
+type TOTPConfig struct {
+	// Issuer is the account label shown in authenticator apps.
+	Issuer        string
+	EncryptionKey string
+}
+
+type OTPConfig struct {
+	Length          int
+	TTL             time.Duration
+	ResendCooldown  time.Duration
+	MaxAttempts     int
+	MaxSendsPerHour int
+}
+
+// RatingConfig controls when ratings can be given and become visible.
+// Participants can rate for Window after the job was completed, and are
+// reminded once if they have not rated ReminderAfter completion. A failed
+// reminder is retried after ReminderRetryDelay, doubling each time, up to
+// ReminderMaxAttempts attempts. A rating is revealed once both participants
+// have rated, or RevealWindow after completion.
+type RatingConfig struct {
+	Window              time.Duration
+	ReminderAfter       time.Duration
+	ReminderRetryDelay  time.Duration
+	ReminderMaxAttempts int
+	RevealWindow        time.Duration
+}
+
+// Validate rejects a rating window that outlasts the reveal window: the
+// first rating would be revealed while the other participant can still rate.
+func (c RatingConfig) Validate() error {
+	if c.Window > c.RevealWindow {
+		return errors.New("RATING_WINDOW must not be longer than RATING_REVEAL_WINDOW")
+	}
+	return nil
+}
+
+// NotifyConfig selects how users are notified.
+type NotifyConfig struct {
+	// Provider is "sms" or "log".
+	Provider string
+}
+
+type SMSConfig struct {
+	// Provider is "log" or "file".
+	Provider string
+	FilePath string
+}
+
+type SchedulerConfig struct {
+	Enabled                bool
+	LeaderTTL              time.Duration
+	JobExpiryInterval      time.Duration
+	TokenCleanupInterval   time.Duration
+	RatingRevealInterval   time.Duration
+	RatingReminderInterval time.Duration
 }
 
 func (d DatabaseConfig) DSN() string {
@@ -67,22 +169,46 @@ func Load() (*Config, error) {
 	viper.SetDefault("REDIS_HOST", "localhost")
 	viper.SetDefault("REDIS_PORT", "6379")
 	viper.SetDefault("REDIS_DB", 0)
+	viper.SetDefault("JWT_ISSUER", "shortjob")
+	viper.SetDefault("JWT_KEYS_DIR", "")
+	viper.SetDefault("JWT_KEY_ACTIVATION_DELAY", "1h")
+	viper.SetDefault("JWT_KEY_RELOAD_INTERVAL", "1m")
+	viper.SetDefault("JWT_REFRESH_KID", "refresh-1")
+	viper.SetDefault("JWT_MFA_KID", "mfa-1")
 	viper.SetDefault("JWT_ACCESS_EXPIRY", "15m")
 	viper.SetDefault("JWT_REFRESH_EXPIRY", "168h")
+	viper.SetDefault("JWT_MFA_EXPIRY", "5m")
+	viper.SetDefault("TOTP_ISSUER", "ShortJob")
 	viper.SetDefault("MAX_SEARCH_RADIUS_KM", 5.0)
+	viper.SetDefault("DEFAULT_TIMEZONE", "Asia/Ho_Chi_Minh")
+	viper.SetDefault("OPEN_JOB_TTL", "72h")
+	viper.SetDefault("OPEN_JOB_START_GRACE", "1h")
+	viper.SetDefault("REQUIRE_PHONE_VERIFICATION", false)
+	viper.SetDefault("LOGIN_FREE_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_LOCKOUT_BASE", "30s")
+	viper.SetDefault("LOGIN_LOCKOUT_MAX", "1h")
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", "24h")
+	viper.SetDefault("OTP_LENGTH", 6)
+	viper.SetDefault("OTP_TTL", "5m")
+	viper.SetDefault("OTP_RESEND_COOLDOWN", "60s")
+	viper.SetDefault("OTP_MAX_ATTEMPTS", 5)
+	viper.SetDefault("RATING_REMINDER_MAX_ATTEMPTS", 3)
+	viper.SetDefault("OTP_MAX_SENDS_PER_HOUR", 5)
+	viper.SetDefault("RATING_WINDOW", "168h")
+	viper.SetDefault("RATING_REMINDER_AFTER", "24h")
+	viper.SetDefault("RATING_REVEAL_WINDOW", "168h")
+	viper.SetDefault("NOTIFY_PROVIDER", "sms")
+	viper.SetDefault("SMS_PROVIDER", "log")
+	viper.SetDefault("SMS_FILE_PATH", "sms.log")
+	viper.SetDefault("SCHEDULER_ENABLED", true)
+	viper.SetDefault("SCHEDULER_LEADER_TTL", "30s")
+	viper.SetDefault("JOB_EXPIRY_INTERVAL", "5m")
+	viper.SetDefault("TOKEN_CLEANUP_INTERVAL", "1h")
+	viper.SetDefault("RATING_REVEAL_INTERVAL", "15m")
+	viper.SetDefault("RATING_REMINDER_INTERVAL", "15m")
 
 	_ = viper.ReadInConfig() // ignore error if .env not found, rely on env vars
 
-	accessExpiry, err := time.ParseDuration(viper.GetString("JWT_ACCESS_EXPIRY"))
-	if err != nil {
-		accessExpiry = 15 * time.Minute
-	}
-
-	refreshExpiry, err := time.ParseDuration(viper.GetString("JWT_REFRESH_EXPIRY"))
-	if err != nil {
-		refreshExpiry = 7 * 24 * time.Hour
-	}
-
 	cfg := &Config{
 		Server: ServerConfig{
 			Port:    viper.GetString("SERVER_PORT"),
@@ -103,14 +229,92 @@ func Load() (*Config, error) {
 			DB:       viper.GetInt("REDIS_DB"),
 		},
 		JWT: JWTConfig{
-			Secret:        viper.GetString("JWT_SECRET"),
-			AccessExpiry:  accessExpiry,
-			RefreshExpiry: refreshExpiry,
+			Issuer:             viper.GetString("JWT_ISSUER"),
+			KeysDir:            viper.GetString("JWT_KEYS_DIR"),
+			KeyActivationDelay: getDuration("JWT_KEY_ACTIVATION_DELAY", time.Hour),
+			KeyReloadInterval:  getDuration("JWT_KEY_RELOAD_INTERVAL", time.Minute),
+			RefreshSecret:      secretOrDerived("JWT_REFRESH_SECRET", "refresh"),
+			RefreshKeyID:       viper.GetString("JWT_REFRESH_KID"),
+			MFASecret:          secretOrDerived("JWT_MFA_SECRET", "mfa"),
+			MFAKeyID:           viper.GetString("JWT_MFA_KID"),
+			AccessExpiry:       getDuration("JWT_ACCESS_EXPIRY", 15*time.Minute),
+			RefreshExpiry:      getDuration("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
+			MFAExpiry:          getDuration("JWT_MFA_EXPIRY", 5*time.Minute),
+
+			RefreshSecretDerived: viper.GetString("JWT_REFRESH_SECRET") == "",
+			MFASecretDerived:     viper.GetString("JWT_MFA_SECRET") == "",
 		},
 		App: AppConfig{
 			MaxSearchRadiusKM: viper.GetFloat64("MAX_SEARCH_RADIUS_KM"),
+			DefaultTimezone:   viper.GetString("DEFAULT_TIMEZONE"),
+			OpenJobTTL:        getDuration("OPEN_JOB_TTL", 72*time.Hour),
+			OpenJobStartGrace: getDuration("OPEN_JOB_START_GRACE", time.Hour),
+
+			RequirePhoneVerification: viper.GetBool("REQUIRE_PHONE_VERIFICATION"),
+		},
+		Login: LoginConfig{
+			FreeAttempts:  viper.GetInt("LOGIN_FREE_ATTEMPTS"),
+			LockoutBase:   getDuration("LOGIN_LOCKOUT_BASE", 30*time.Second),
+			LockoutMax:    getDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+			FailureWindow: getDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+		},
+		TOTP: TOTPConfig{
+			Issuer:        viper.GetString("TOTP_ISSUER"),
+			EncryptionKey: viper.GetString("TOTP_ENCRYPTION_KEY"),
+		},
+		OTP: OTPConfig{
+			Length:          viper.GetInt("OTP_LENGTH"),
+			TTL:             getDuration("OTP_TTL", 5*time.Minute),
+			ResendCooldown:  getDuration("OTP_RESEND_COOLDOWN", time.Minute),
+			MaxAttempts:     viper.GetInt("OTP_MAX_ATTEMPTS"),
+			MaxSendsPerHour: viper.GetInt("OTP_MAX_SENDS_PER_HOUR"),
+		},
+		Rating: RatingConfig{
+			Window:              getDuration("RATING_WINDOW", 7*24*time.Hour),
+			ReminderAfter:       getDuration("RATING_REMINDER_AFTER", 24*time.Hour),
+			ReminderRetryDelay:  getDuration("RATING_REMINDER_RETRY_DELAY", time.Hour),
+			ReminderMaxAttempts: viper.GetInt("RATING_REMINDER_MAX_ATTEMPTS"),
+			RevealWindow:        getDuration("RATING_REVEAL_WINDOW", 7*24*time.Hour),
+		},
+		Notify: NotifyConfig{
+			Provider: viper.GetString("NOTIFY_PROVIDER"),
+		},
+		SMS: SMSConfig{
+			Provider: viper.GetString("SMS_PROVIDER"),
+			FilePath: viper.GetString("SMS_FILE_PATH"),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:                viper.GetBool("SCHEDULER_ENABLED"),
+			LeaderTTL:              getDuration("SCHEDULER_LEADER_TTL", 30*time.Second),
+			JobExpiryInterval:      getDuration("JOB_EXPIRY_INTERVAL", 5*time.Minute),
+			TokenCleanupInterval:   getDuration("TOKEN_CLEANUP_INTERVAL", time.Hour),
+			RatingRevealInterval:   getDuration("RATING_REVEAL_INTERVAL", 15*time.Minute),
+			RatingReminderInterval: getDuration("RATING_REMINDER_INTERVAL", 15*time.Minute),
 		},
 	}
 
+	if err := cfg.Rating.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
+
+// getDuration parses a duration setting, falling back when it is malformed.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(viper.GetString(key))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// secretOrDerived returns the secret configured under key. When it is unset,
+// a secret is derived from the legacy JWT_SECRET and label.
+func secretOrDerived(key, label string) string {
+	if secret := viper.GetString(key); secret != "" {
+		return secret
+	}
+	mac := hmac.New(sha256.New, []byte(viper.GetString("JWT_SECRET")))
+	mac.Write([]byte("shortjob-jwt-" + label))
+	return hex.EncodeToString(mac.Sum(nil))
+}
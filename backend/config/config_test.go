@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRatingConfigValidate(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name         string
+		window       time.Duration
+		revealWindow time.Duration
+		wantErr      bool
+	}{
+		{"reveal after rating closes", 7 * day, 14 * day, false},
+		{"reveal when rating closes", 7 * day, 7 * day, false},
+		{"reveal while rating is open", 14 * day, 7 * day, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := RatingConfig{Window: tt.window, RevealWindow: tt.revealWindow}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsRatingWindowPastReveal(t *testing.T) {
+	t.Setenv("RATING_WINDOW", "336h")
+	t.Setenv("RATING_REVEAL_WINDOW", "168h")
+
+	if _, err := Load(); err == nil {
+		t.Error("Load succeeded with RATING_WINDOW longer than RATING_REVEAL_WINDOW")
+	}
+}
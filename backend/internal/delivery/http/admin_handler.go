@@ -0,0 +1,196 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/usecase"
+)
+
+type AdminHandler struct {
+	adminUC *usecase.AdminUseCase
+}
+
+func NewAdminHandler(adminUC *usecase.AdminUseCase) *AdminHandler {
+	return &AdminHandler{adminUC: adminUC}
+}
+
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	var query usecase.AdminUserQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.adminUC.SearchUsers(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AdminHandler) SearchJobs(c *gin.Context) {
+	var query usecase.AdminJobQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.adminUC.SearchJobs(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AdminHandler) AuditLogs(c *gin.Context) {
+	var query usecase.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.adminUC.AuditLogs(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AdminHandler) Stats(c *gin.Context) {
+	stats, err := h.adminUC.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input usecase.SuspendUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+
+	user, err := h.adminUC.SuspendUser(adminID, userID, input, clientInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) SetUserStatus(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input usecase.SetUserStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+
+	user, err := h.adminUC.SetUserStatus(adminID, userID, input, clientInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+
+	user, err := h.adminUC.UnsuspendUser(adminID, userID, clientInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) CancelJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	var input usecase.CancelJobInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+
+	job, err := h.adminUC.CancelJob(adminID, jobID, input, clientInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DeleteRating takes the reason for the audit log from the reason query
+// parameter, as DELETE requests carry no body.
+func (h *AdminHandler) DeleteRating(c *gin.Context) {
+	ratingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rating id"})
+		return
+	}
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.adminUC.DeleteRating(adminID, ratingID, c.Query("reason"), clientInfo(c)); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound), errors.Is(err, usecase.ErrJobNotFound), errors.Is(err, usecase.ErrRatingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrStatusUnchanged), errors.Is(err, usecase.ErrNotSuspended):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
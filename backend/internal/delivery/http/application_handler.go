@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +27,10 @@ func (h *ApplicationHandler) Apply(c *gin.Context) {
 	workerID := c.MustGet("user_id").(uuid.UUID)
 
 	app, err := h.appUC.Apply(jobID, workerID)
+	if errors.Is(err, usecase.ErrPhoneNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -44,6 +49,10 @@ func (h *ApplicationHandler) Accept(c *gin.Context) {
 	employerID := c.MustGet("user_id").(uuid.UUID)
 
 	app, err := h.appUC.Accept(appID, employerID)
+	if errors.Is(err, usecase.ErrJobAlreadyAssigned) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -85,3 +94,39 @@ func (h *ApplicationHandler) GetByJobID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"applications": apps})
 }
+
+func (h *ApplicationHandler) Withdraw(c *gin.Context) {
+	appID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application id"})
+		return
+	}
+
+	workerID := c.MustGet("user_id").(uuid.UUID)
+
+	app, err := h.appUC.Withdraw(appID, workerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, app)
+}
+
+func (h *ApplicationHandler) GetMyApplications(c *gin.Context) {
+	var query usecase.MyApplicationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workerID := c.MustGet("user_id").(uuid.UUID)
+
+	page, err := h.appUC.GetByWorkerID(workerID, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
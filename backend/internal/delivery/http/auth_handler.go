@@ -1,9 +1,12 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/work-near-me/backend/internal/usecase"
 )
 
@@ -22,7 +25,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authUC.Register(input)
+	resp, err := h.authUC.Register(input, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -38,15 +41,46 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authUC.Login(input)
+	resp, err := h.authUC.Login(input, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var input usecase.MFALoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authUC.LoginMFA(input, clientInfo(c))
+	if err != nil {
+		respondLoginError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// respondLoginError maps errors from the login steps.
+func respondLoginError(c *gin.Context, err error) {
+	var locked *usecase.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, usecase.ErrAccountSuspended) || errors.Is(err, usecase.ErrAccountBanned) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
+
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var input usecase.RefreshInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -54,11 +88,167 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authUC.Refresh(input)
+	resp, err := h.authUC.Refresh(input, clientInfo(c))
 	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var input usecase.RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authUC.Logout(input); err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.authUC.LogoutAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) SwitchRole(c *gin.Context) {
+	var input usecase.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	sessionID := c.MustGet("session_id").(uuid.UUID)
+
+	resp, err := h.authUC.SwitchRole(userID, sessionID, input)
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AuthHandler) AddRole(c *gin.Context) {
+	var input usecase.AddRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	sessionID := c.MustGet("session_id").(uuid.UUID)
+
+	resp, err := h.authUC.AddRole(userID, sessionID, input)
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
+
+func respondRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrRoleNotHeld):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrRoleAlreadyHeld):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *AuthHandler) SendPhoneCode(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	sent, err := h.authUC.SendPhoneCode(userID)
+	if err != nil {
+		respondOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sent)
+}
+
+func (h *AuthHandler) VerifyPhone(c *gin.Context) {
+	var input usecase.VerifyPhoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	user, err := h.authUC.VerifyPhone(userID, input)
+	if err != nil {
+		respondOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var input usecase.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sent, err := h.authUC.ForgotPassword(input)
+	if err != nil {
+		respondOTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, sent)
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var input usecase.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authUC.ResetPassword(input, clientInfo(c)); err != nil {
+		respondOTPError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondOTPError maps errors from sending or checking one-time codes.
+func respondOTPError(c *gin.Context, err error) {
+	var cooldown *usecase.CooldownError
+	switch {
+	case errors.As(err, &cooldown):
+		c.Header("Retry-After", strconv.Itoa(int(cooldown.RetryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrOTPAttemptsExceeded):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrPhoneAlreadyVerified):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func clientInfo(c *gin.Context) usecase.ClientInfo {
+	return usecase.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +28,10 @@ func (h *JobHandler) Create(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	job, err := h.jobUC.Create(userID, input)
+	if errors.Is(err, usecase.ErrPhoneNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -41,13 +47,17 @@ func (h *JobHandler) GetNearby(c *gin.Context) {
 		return
 	}
 
-	jobs, err := h.jobUC.GetNearby(query)
+	page, err := h.jobUC.GetNearby(query)
+	if errors.Is(err, usecase.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *JobHandler) GetByID(c *gin.Context) {
@@ -84,6 +94,10 @@ func (h *JobHandler) Assign(c *gin.Context) {
 	employerID := c.MustGet("user_id").(uuid.UUID)
 
 	job, err := h.jobUC.Assign(jobID, input.WorkerID, employerID)
+	if errors.Is(err, usecase.ErrJobAlreadyAssigned) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -110,6 +124,57 @@ func (h *JobHandler) Complete(c *gin.Context) {
 	c.JSON(http.StatusOK, job)
 }
 
+func (h *JobHandler) Cancel(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	var input usecase.CancelJobInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employerID := c.MustGet("user_id").(uuid.UUID)
+
+	job, err := h.jobUC.Cancel(jobID, employerID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *JobHandler) Unassign(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var input usecase.UnassignInput
+	if c.Request.Body != http.NoBody {
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	job, err := h.jobUC.Unassign(jobID, userID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
 func (h *JobHandler) GetMyJobs(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
@@ -5,10 +5,14 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/work-near-me/backend/internal/repository"
 	"github.com/work-near-me/backend/pkg"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware accepts only access tokens issued by tokens whose session
+// has not been revoked and whose user has not been suspended, banned or
+// deleted since.
+func AuthMiddleware(tokens *pkg.TokenIssuer, denylist *repository.DenylistRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -22,18 +26,30 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := pkg.ValidateToken(parts[1], jwtSecret)
+		claims, err := tokens.ValidateAccessToken(parts[1])
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			return
 		}
 
+		// When Redis is unavailable tokens are accepted, as with rate
+		// limiting; revoked sessions and blocked users then keep access
+		// until their tokens expire
+		if denied, err := denylist.Denied(claims.SessionID, claims.UserID); err == nil && denied {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session has been revoked or account is no longer active"})
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
 		c.Set("user_role", claims.Role)
 		c.Next()
 	}
 }
 
+// RoleMiddleware admits users whose active role, as carried by their access
+// token, is one of roles. Holding a role without switching to it is not
+// enough.
 func RoleMiddleware(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -33,3 +34,54 @@ func (h *RatingHandler) Create(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, rating)
 }
+
+func (h *RatingHandler) ListForUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var query usecase.UserRatingsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.ratingUC.ListReceived(userID, query)
+	if err != nil {
+		respondRatingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *RatingHandler) ListForJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	ratings, err := h.ratingUC.ListForJob(jobID, userID)
+	if err != nil {
+		respondRatingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
+
+func respondRatingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound), errors.Is(err, usecase.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotJobParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -7,32 +7,49 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/work-near-me/backend/internal/delivery/http/middleware"
+	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg"
 )
 
 type Router struct {
 	engine      *gin.Engine
 	authH       *AuthHandler
+	twoFactorH  *TwoFactorHandler
+	sessionH    *SessionHandler
+	userH       *UserHandler
 	jobH        *JobHandler
 	appH        *ApplicationHandler
 	ratingH     *RatingHandler
-	jwtSecret   string
+	adminH      *AdminHandler
+	tokens      *pkg.TokenIssuer
+	denylist    *repository.DenylistRepository
 	redisClient *redis.Client
 }
 
 func NewRouter(
 	authH *AuthHandler,
+	twoFactorH *TwoFactorHandler,
+	sessionH *SessionHandler,
+	userH *UserHandler,
 	jobH *JobHandler,
 	appH *ApplicationHandler,
 	ratingH *RatingHandler,
-	jwtSecret string,
+	adminH *AdminHandler,
+	tokens *pkg.TokenIssuer,
+	denylist *repository.DenylistRepository,
 	redisClient *redis.Client,
 ) *Router {
 	return &Router{
 		authH:       authH,
+		twoFactorH:  twoFactorH,
+		sessionH:    sessionH,
+		userH:       userH,
 		jobH:        jobH,
 		appH:        appH,
 		ratingH:     ratingH,
-		jwtSecret:   jwtSecret,
+		adminH:      adminH,
+		tokens:      tokens,
+		denylist:    denylist,
 		redisClient: redisClient,
 	}
 }
@@ -43,7 +60,7 @@ func (r *Router) Setup() *gin.Engine {
 	// CORS
 	engine.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -55,6 +72,13 @@ func (r *Router) Setup() *gin.Engine {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Public keys for services verifying our access tokens. Keep the cache
+	// short so a rotated-in key is picked up well within the activation delay.
+	engine.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(200, r.tokens.JWKS())
+	})
+
 	api := engine.Group("/api")
 	{
 		// Auth routes (public)
@@ -63,13 +87,51 @@ func (r *Router) Setup() *gin.Engine {
 			loginRateLimit := middleware.RateLimitMiddleware(r.redisClient, 5, time.Minute)
 			auth.POST("/register", r.authH.Register)
 			auth.POST("/login", loginRateLimit, r.authH.Login)
+			auth.POST("/login/mfa", loginRateLimit, r.authH.LoginMFA)
 			auth.POST("/refresh", r.authH.Refresh)
+			auth.POST("/logout", r.authH.Logout)
+
+			// Per-IP limits; the OTP service also limits sends per phone
+			// number and guesses per code
+			auth.POST("/password/forgot", middleware.RateLimitMiddleware(r.redisClient, 5, time.Hour), r.authH.ForgotPassword)
+			auth.POST("/password/reset", middleware.RateLimitMiddleware(r.redisClient, 10, 15*time.Minute), r.authH.ResetPassword)
 		}
 
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(r.jwtSecret))
+		protected.Use(middleware.AuthMiddleware(r.tokens, r.denylist))
 		{
+			protected.POST("/auth/logout-all", r.authH.LogoutAll)
+			protected.PUT("/auth/role", r.authH.SwitchRole)
+			protected.POST("/auth/roles", r.authH.AddRole)
+			protected.GET("/auth/sessions", r.sessionH.List)
+			protected.DELETE("/auth/sessions/:id", r.sessionH.Revoke)
+			protected.POST("/auth/phone/send", r.authH.SendPhoneCode)
+			protected.POST("/auth/phone/resend", r.authH.SendPhoneCode)
+			protected.POST("/auth/phone/verify", r.authH.VerifyPhone)
+
+			// Two-factor authentication
+			// Per-IP limits; wrong codes also count towards the per-phone
+			// login lockout
+			twoFactor := protected.Group("/auth/2fa")
+			{
+				twoFactorRateLimit := middleware.RateLimitMiddleware(r.redisClient, 10, 15*time.Minute)
+				twoFactor.GET("", r.twoFactorH.Status)
+				twoFactor.POST("/enroll", twoFactorRateLimit, r.twoFactorH.Enroll)
+				twoFactor.POST("/confirm", twoFactorRateLimit, r.twoFactorH.Confirm)
+				twoFactor.POST("/disable", twoFactorRateLimit, r.twoFactorH.Disable)
+				twoFactor.POST("/recovery-codes", twoFactorRateLimit, r.twoFactorH.RegenerateRecoveryCodes)
+			}
+
+			// Profiles
+			users := protected.Group("/users")
+			{
+				users.GET("/me", r.userH.GetMe)
+				users.PATCH("/me", r.userH.UpdateMe)
+				users.GET("/:id", r.userH.GetByID)
+				users.GET("/:id/ratings", r.ratingH.ListForUser)
+			}
+
 			// Job routes
 			jobs := protected.Group("/jobs")
 			{
@@ -80,6 +142,9 @@ func (r *Router) Setup() *gin.Engine {
 				jobs.GET("/:id", r.jobH.GetByID)
 				jobs.PUT("/:id/assign", middleware.RoleMiddleware("employer"), r.jobH.Assign)
 				jobs.PUT("/:id/complete", middleware.RoleMiddleware("employer"), r.jobH.Complete)
+				jobs.PUT("/:id/cancel", middleware.RoleMiddleware("employer"), r.jobH.Cancel)
+				jobs.PUT("/:id/unassign", r.jobH.Unassign)
+				jobs.GET("/:id/ratings", r.ratingH.ListForJob)
 
 				// Application routes under jobs
 				jobs.POST("/:id/apply", middleware.RoleMiddleware("worker"), r.appH.Apply)
@@ -89,12 +154,28 @@ func (r *Router) Setup() *gin.Engine {
 			// Application management
 			applications := protected.Group("/applications")
 			{
+				applications.GET("/my", middleware.RoleMiddleware("worker"), r.appH.GetMyApplications)
+				applications.PUT("/:id/withdraw", middleware.RoleMiddleware("worker"), r.appH.Withdraw)
 				applications.PUT("/:id/accept", middleware.RoleMiddleware("employer"), r.appH.Accept)
 				applications.PUT("/:id/reject", middleware.RoleMiddleware("employer"), r.appH.Reject)
 			}
 
 			// Rating routes
 			protected.POST("/ratings", r.ratingH.Create)
+
+			// Moderation
+			admin := protected.Group("/admin", middleware.RoleMiddleware("admin"))
+			{
+				admin.GET("/stats", r.adminH.Stats)
+				admin.GET("/users", r.adminH.SearchUsers)
+				admin.POST("/users/:id/suspend", r.adminH.SuspendUser)
+				admin.POST("/users/:id/unsuspend", r.adminH.UnsuspendUser)
+				admin.PUT("/users/:id/status", r.adminH.SetUserStatus)
+				admin.GET("/jobs", r.adminH.SearchJobs)
+				admin.POST("/jobs/:id/cancel", r.adminH.CancelJob)
+				admin.DELETE("/ratings/:id", r.adminH.DeleteRating)
+				admin.GET("/audit-logs", r.adminH.AuditLogs)
+			}
 		}
 	}
 
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/usecase"
+)
+
+type SessionHandler struct {
+	sessionUC *usecase.SessionUseCase
+}
+
+func NewSessionHandler(sessionUC *usecase.SessionUseCase) *SessionHandler {
+	return &SessionHandler{sessionUC: sessionUC}
+}
+
+func (h *SessionHandler) List(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	sessionID := c.MustGet("session_id").(uuid.UUID)
+
+	sessions, err := h.sessionUC.List(userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.sessionUC.Revoke(userID, sessionID); err != nil {
+		if errors.Is(err, usecase.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
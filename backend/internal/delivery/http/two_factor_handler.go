@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/usecase"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUC *usecase.TwoFactorUseCase
+}
+
+func NewTwoFactorHandler(twoFactorUC *usecase.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorUC: twoFactorUC}
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	status, err := h.twoFactorUC.Status(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	enrollment, err := h.twoFactorUC.Enroll(userID)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var input usecase.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	codes, err := h.twoFactorUC.Confirm(userID, input, clientInfo(c))
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var input usecase.DisableTwoFactorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.twoFactorUC.Disable(userID, input, clientInfo(c)); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var input usecase.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	codes, err := h.twoFactorUC.RegenerateRecoveryCodes(userID, input, clientInfo(c))
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func respondTwoFactorError(c *gin.Context, err error) {
+	var locked *usecase.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch {
+	case errors.Is(err, usecase.ErrTwoFactorEnabled), errors.Is(err, usecase.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
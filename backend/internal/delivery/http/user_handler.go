@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/usecase"
+)
+
+type UserHandler struct {
+	userUC *usecase.UserUseCase
+}
+
+func NewUserHandler(userUC *usecase.UserUseCase) *UserHandler {
+	return &UserHandler{userUC: userUC}
+}
+
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	profile, err := h.userUC.GetProfile(userID, userID)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var input usecase.UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	profile, err := h.userUC.UpdateProfile(userID, input)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	viewerID := c.MustGet("user_id").(uuid.UUID)
+
+	profile, err := h.userUC.GetProfile(id, viewerID)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func respondUserError(c *gin.Context, err error) {
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
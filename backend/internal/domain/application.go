@@ -10,19 +10,32 @@ import (
 type ApplicationStatus string
 
 const (
-	ApplicationStatusPending  ApplicationStatus = "pending"
-	ApplicationStatusAccepted ApplicationStatus = "accepted"
-	ApplicationStatusRejected ApplicationStatus = "rejected"
+	ApplicationStatusPending   ApplicationStatus = "pending"
+	ApplicationStatusAccepted  ApplicationStatus = "accepted"
+	ApplicationStatusRejected  ApplicationStatus = "rejected"
+	ApplicationStatusWithdrawn ApplicationStatus = "withdrawn"
+)
+
+// RejectionReason tells a worker why their application was rejected.
+type RejectionReason string
+
+const (
+	RejectionReasonDeclined   RejectionReason = "declined"
+	RejectionReasonFilled     RejectionReason = "filled"
+	RejectionReasonCancelled  RejectionReason = "cancelled"
+	RejectionReasonUnassigned RejectionReason = "unassigned"
+	RejectionReasonExpired    RejectionReason = "expired"
 )
 
 type Application struct {
-	ID        uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
-	JobID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"job_id"`
-	Job       *Job              `gorm:"foreignKey:JobID" json:"job,omitempty"`
-	WorkerID  uuid.UUID         `gorm:"type:uuid;not null;index" json:"worker_id"`
-	Worker    *User             `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
-	Status    ApplicationStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	CreatedAt time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	ID              uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	JobID           uuid.UUID         `gorm:"type:uuid;not null;index" json:"job_id"`
+	Job             *Job              `gorm:"foreignKey:JobID" json:"job,omitempty"`
+	WorkerID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"worker_id"`
+	Worker          *User             `gorm:"foreignKey:WorkerID" json:"worker,omitempty"`
+	Status          ApplicationStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	RejectionReason RejectionReason   `gorm:"type:varchar(30)" json:"rejection_reason,omitempty"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
 }
 
 func (a *Application) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionUserSuspended  AuditAction = "user_suspended"
+	AuditActionUserBanned     AuditAction = "user_banned"
+	AuditActionUserDeleted    AuditAction = "user_deleted"
+	AuditActionUserReinstated AuditAction = "user_reinstated"
+	AuditActionJobCancelled   AuditAction = "job_cancelled"
+	AuditActionRatingDeleted  AuditAction = "rating_deleted"
+	AuditActionAdminGranted   AuditAction = "admin_granted"
+	AuditActionAdminRevoked   AuditAction = "admin_revoked"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetUser   AuditTargetType = "user"
+	AuditTargetJob    AuditTargetType = "job"
+	AuditTargetRating AuditTargetType = "rating"
+)
+
+// AuditLog records a moderation action. AdminID is nil for actions taken
+// from the command line. Details holds a JSON snapshot of anything the
+// action deleted.
+type AuditLog struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	AdminID    *uuid.UUID      `gorm:"type:uuid" json:"admin_id,omitempty"`
+	Action     AuditAction     `gorm:"type:varchar(30);not null" json:"action"`
+	TargetType AuditTargetType `gorm:"type:varchar(20);not null" json:"target_type"`
+	TargetID   uuid.UUID       `gorm:"type:uuid;not null" json:"target_id"`
+	Reason     string          `gorm:"type:text" json:"reason,omitempty"`
+	Details    string          `gorm:"type:text" json:"details,omitempty"`
+	IP         string          `gorm:"type:varchar(45)" json:"ip,omitempty"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (l *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuthEventType string
+
+const (
+	AuthEventLoginSucceeded AuthEventType = "login_succeeded"
+	AuthEventLoginFailed    AuthEventType = "login_failed"
+	// AuthEventLoginBlocked is a login attempt rejected because the phone
+	// number was locked out, without checking the password.
+	AuthEventLoginBlocked  AuthEventType = "login_blocked"
+	AuthEventAccountLocked AuthEventType = "account_locked"
+	AuthEventPasswordReset AuthEventType = "password_reset"
+	AuthEventMFAFailed     AuthEventType = "mfa_failed"
+	AuthEventMFAEnabled    AuthEventType = "mfa_enabled"
+	AuthEventMFADisabled   AuthEventType = "mfa_disabled"
+)
+
+// AuthEvent is an audit record of an authentication attempt or account
+// change. UserID is nil when the phone number is not registered.
+type AuthEvent struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    *uuid.UUID    `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Phone     string        `gorm:"type:varchar(20);not null;index" json:"phone"`
+	Type      AuthEventType `gorm:"type:varchar(30);not null" json:"type"`
+	IP        string        `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent string        `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt time.Time     `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (e *AuthEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ const (
 	JobStatusAssigned  JobStatus = "assigned"
 	JobStatusDone      JobStatus = "done"
 	JobStatusCancelled JobStatus = "cancelled"
+	JobStatusExpired   JobStatus = "expired"
 )
 
 type Job struct {
@@ -24,16 +25,35 @@ type Job struct {
 	Description      string     `gorm:"type:text" json:"description"`
 	HourlyRate       float64    `gorm:"type:double precision" json:"hourly_rate"`
 	TotalPayment     float64    `gorm:"type:double precision" json:"total_payment"`
+	StartTime        *time.Time `gorm:"index" json:"start_time,omitempty"`
+	EndTime          *time.Time `json:"end_time,omitempty"`
+	Timezone         string     `gorm:"type:varchar(64)" json:"timezone,omitempty"`
+	EstimatedHours   float64    `gorm:"type:double precision" json:"estimated_hours"`
 	Latitude         float64    `gorm:"type:double precision;not null" json:"latitude"`
 	Longitude        float64    `gorm:"type:double precision;not null" json:"longitude"`
 	Status           JobStatus  `gorm:"type:varchar(20);not null;default:'open'" json:"status"`
 	AssignedWorkerID *uuid.UUID `gorm:"type:uuid" json:"assigned_worker_id,omitempty"`
 	AssignedWorker   *User      `gorm:"foreignKey:AssignedWorkerID" json:"assigned_worker,omitempty"`
+	CancelReason     string     `gorm:"type:text" json:"cancel_reason,omitempty"`
+	CancelledAt      *time.Time `json:"cancelled_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
 	EmployerRated    bool       `gorm:"-" json:"employer_rated"`
 	WorkerRated      bool       `gorm:"-" json:"worker_rated"`
+	RatingDeadline   *time.Time `gorm:"-" json:"rating_deadline,omitempty"`
 	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
+// JobUnassignment records a worker leaving an assigned job, either removed
+// by the employer or backing out themselves.
+type JobUnassignment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	JobID     uuid.UUID `gorm:"type:uuid;not null;index" json:"job_id"`
+	WorkerID  uuid.UUID `gorm:"type:uuid;not null;index" json:"worker_id"`
+	ByUserID  uuid.UUID `gorm:"type:uuid;not null" json:"by_user_id"`
+	Reason    string    `gorm:"type:text" json:"reason"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
 // JobWithDistance is used for nearby queries
 type JobWithDistance struct {
 	Job
@@ -46,3 +66,10 @@ func (j *Job) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (u *JobUnassignment) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package domain
+
+// OTPPurpose scopes a one-time code to the flow it was sent for, so a code
+// sent for one purpose cannot be used for another.
+type OTPPurpose string
+
+const (
+	OTPPurposePhoneVerification OTPPurpose = "phone_verification"
+	OTPPurposePasswordReset     OTPPurpose = "password_reset"
+)
@@ -7,17 +7,24 @@ import (
 	"gorm.io/gorm"
 )
 
+// Rating is one participant's rating of the other after a job. ToRole is
+// the role the rated user had in the job, which decides the aggregate it
+// counts towards. A rating is hidden, and left out of the aggregate, until
+// RevealedAt: once both participants have rated, or when the reveal window
+// after completion ends.
 type Rating struct {
-	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	JobID      uuid.UUID `gorm:"type:uuid;not null;index" json:"job_id"`
-	Job        *Job      `gorm:"foreignKey:JobID" json:"job,omitempty"`
-	FromUserID uuid.UUID `gorm:"type:uuid;not null;index" json:"from_user_id"`
-	FromUser   *User     `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
-	ToUserID   uuid.UUID `gorm:"type:uuid;not null;index" json:"to_user_id"`
-	ToUser     *User     `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
-	Score      int       `gorm:"not null;check:score >= 1 AND score <= 5" json:"score"`
-	Comment    string    `gorm:"type:text" json:"comment"`
-	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	JobID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"job_id"`
+	Job        *Job       `gorm:"foreignKey:JobID" json:"job,omitempty"`
+	FromUserID uuid.UUID  `gorm:"type:uuid;not null;index" json:"from_user_id"`
+	FromUser   *User      `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+	ToUserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"to_user_id"`
+	ToUser     *User      `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
+	ToRole     UserRole   `gorm:"type:varchar(20);not null" json:"to_role"`
+	Score      int        `gorm:"not null;check:score >= 1 AND score <= 5" json:"score"`
+	Comment    string     `gorm:"type:text" json:"comment"`
+	RevealedAt *time.Time `json:"revealed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
 func (r *Rating) BeforeCreate(tx *gorm.DB) error {
@@ -26,3 +33,13 @@ func (r *Rating) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// RatingReminder records the attempts to remind a participant of a job to
+// rate the other. SentAt is set once one of them was delivered.
+type RatingReminder struct {
+	JobID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"job_id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+}
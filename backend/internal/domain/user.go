@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,19 +15,99 @@ type UserRole string
 const (
 	RoleEmployer UserRole = "employer"
 	RoleWorker   UserRole = "worker"
+	// RoleAdmin is granted from the command line, never at registration.
+	RoleAdmin UserRole = "admin"
 )
 
+// UserStatus says whether an account may be used. Only active accounts can
+// log in or call the API.
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusBanned    UserStatus = "banned"
+	UserStatusDeleted   UserStatus = "deleted"
+)
+
+// UserRoles is the set of roles an account holds, stored as a Postgres text
+// array.
+type UserRoles []UserRole
+
+func (r UserRoles) Has(role UserRole) bool {
+	for _, held := range r {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (r UserRoles) Value() (driver.Value, error) {
+	parts := make([]string, len(r))
+	for i, role := range r {
+		parts[i] = string(role)
+	}
+	return "{" + strings.Join(parts, ",") + "}", nil
+}
+
+func (r *UserRoles) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case nil:
+		*r = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into UserRoles", src)
+	}
+
+	text = strings.Trim(text, "{}")
+	if text == "" {
+		*r = nil
+		return nil
+	}
+	parts := strings.Split(text, ",")
+	roles := make(UserRoles, len(parts))
+	for i, part := range parts {
+		roles[i] = UserRole(part)
+	}
+	*r = roles
+	return nil
+}
+
+// User is an account. An account may hold several roles; Role is the one it
+// currently acts in, which access tokens carry. Ratings are kept apart per
+// role, so reputations as employer and as worker do not mix.
+//
+// TOTPSecret holds the encrypted two-factor secret from enrollment on, while
+// TOTPEnabled only turns on once the user has confirmed a code from it.
+// StatusChangedAt and StatusReason describe the latest status change.
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	Name         string    `gorm:"type:varchar(255);not null" json:"name"`
-	Phone        string    `gorm:"type:varchar(20);uniqueIndex;not null" json:"phone"`
-	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
-	Role         UserRole  `gorm:"type:varchar(20);not null" json:"role"`
-	Latitude     float64   `gorm:"type:double precision" json:"latitude"`
-	Longitude    float64   `gorm:"type:double precision" json:"longitude"`
-	RatingAvg    float64   `gorm:"type:double precision;default:0" json:"rating_avg"`
-	RatingCount  int       `gorm:"default:0" json:"rating_count"`
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID                  uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name                string     `gorm:"type:varchar(255);not null" json:"name"`
+	Bio                 string     `gorm:"type:text;not null;default:''" json:"bio"`
+	Phone               string     `gorm:"type:varchar(20);uniqueIndex;not null" json:"phone"`
+	PhoneVerified       bool       `gorm:"not null;default:false" json:"phone_verified"`
+	PasswordHash        string     `gorm:"type:varchar(255);not null" json:"-"`
+	TOTPSecret          string     `gorm:"type:text" json:"-"`
+	TOTPEnabled         bool       `gorm:"not null;default:false" json:"totp_enabled"`
+	TOTPLastStep        int64      `gorm:"not null;default:0" json:"-"`
+	Role                UserRole   `gorm:"type:varchar(20);not null" json:"role"`
+	Roles               UserRoles  `gorm:"type:text[];not null" json:"roles"`
+	Latitude            float64    `gorm:"type:double precision" json:"latitude"`
+	Longitude           float64    `gorm:"type:double precision" json:"longitude"`
+	EmployerRatingAvg   float64    `gorm:"type:double precision;not null;default:0" json:"employer_rating_avg"`
+	EmployerRatingCount int        `gorm:"not null;default:0" json:"employer_rating_count"`
+	WorkerRatingAvg     float64    `gorm:"type:double precision;not null;default:0" json:"worker_rating_avg"`
+	WorkerRatingCount   int        `gorm:"not null;default:0" json:"worker_rating_count"`
+	Status              UserStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	StatusChangedAt     *time.Time `json:"status_changed_at,omitempty"`
+	StatusReason        string     `gorm:"type:text" json:"status_reason,omitempty"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
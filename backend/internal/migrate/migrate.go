@@ -0,0 +1,296 @@
+// Package migrate applies the ordered, versioned SQL migrations embedded in
+// the binary and records them in the schema_migrations table.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockID is the Postgres advisory lock key taken while a migration runs, so
+// replicas migrating at the same time apply each version exactly once.
+const lockID = 7304251
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrSchemaBehind is returned by Check when embedded migrations have not
+// been applied to the database yet.
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New loads every migration in fsys. Each version needs both an up and a
+// down file.
+func New(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest embedded migration version.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration in version order.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.To(m.Latest())
+}
+
+// Down reverts the most recently applied migration. It returns nil when
+// nothing is applied, or when another process reverted it first.
+func (m *Migrator) Down() (*Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[m.migrations[i].Version]; ok {
+			target := m.migrations[i]
+			reverted, err := m.revert(target)
+			if err != nil || !reverted {
+				return nil, err
+			}
+			return &target, nil
+		}
+	}
+	return nil, nil
+}
+
+// To brings the schema to exactly version: pending migrations up to it are
+// applied, and applied migrations above it are reverted newest first. It
+// returns the migrations this process ran, leaving out any another process
+// ran while this one waited for the lock.
+func (m *Migrator) To(version int64) ([]Migration, error) {
+	if version != 0 && !m.has(version) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; ok && mig.Version > version {
+			reverted, err := m.revert(mig)
+			if err != nil {
+				return ran, err
+			}
+			if reverted {
+				ran = append(ran, mig)
+			}
+		}
+	}
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+			applied, err := m.apply(mig)
+			if err != nil {
+				return ran, err
+			}
+			if applied {
+				ran = append(ran, mig)
+			}
+		}
+	}
+	return ran, nil
+}
+
+// Status lists every embedded migration with the time it was applied, if
+// it was.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i] = Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns ErrSchemaBehind when any embedded migration is unapplied. A
+// database that is ahead of this binary is accepted, so older replicas can
+// keep serving during a rolling deploy.
+func (m *Migrator) Check() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			return fmt.Errorf("%w: migration %d_%s is not applied", ErrSchemaBehind, mig.Version, mig.Name)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) has(version int64) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+}
+
+func (m *Migrator) applied() (map[int64]time.Time, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Version   int64
+		AppliedAt time.Time
+	}
+	if err := m.db.Raw("SELECT version, applied_at FROM schema_migrations").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+// apply runs mig up and reports whether it did, which it does not when mig
+// was applied by the time the lock was taken.
+func (m *Migrator) apply(mig Migration) (bool, error) {
+	ran := false
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		done, err := lockAndCheck(tx, mig.Version)
+		if err != nil || done {
+			return err
+		}
+
+		if err := tx.Exec(mig.Up).Error; err != nil {
+			return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+		}
+		if err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", mig.Version, mig.Name).Error; err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	return ran && err == nil, err
+}
+
+// revert runs mig down and reports whether it did, which it does not when
+// mig was no longer applied by the time the lock was taken.
+func (m *Migrator) revert(mig Migration) (bool, error) {
+	ran := false
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		done, err := lockAndCheck(tx, mig.Version)
+		if err != nil || !done {
+			return err
+		}
+
+		if err := tx.Exec(mig.Down).Error; err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+		}
+		if err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", mig.Version).Error; err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	return ran && err == nil, err
+}
+
+// lockAndCheck takes the migration lock for the rest of the transaction and
+// reports whether version is applied, now that no one else can change it.
+func lockAndCheck(tx *gorm.DB, version int64) (bool, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+		return false, err
+	}
+
+	var count int64
+	err := tx.Raw("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", version).Scan(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,115 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/work-near-me/backend/migrations"
+)
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestNew(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_later.up.sql":     file("CREATE TABLE later ();"),
+		"0010_later.down.sql":   file("DROP TABLE later;"),
+		"0002_second.up.sql":    file("CREATE TABLE second ();"),
+		"0002_second.down.sql":  file("DROP TABLE second;"),
+		"0001_first.up.sql":     file("CREATE TABLE first ();"),
+		"0001_first.down.sql":   file("DROP TABLE first;"),
+		"embed.go":              file("package migrations"),
+		"0003_notes.txt":        file("not a migration"),
+		"draft_0004.up.sql":     file("not a migration either"),
+		"0005_missing-dash.sql": file("wrong name"),
+	}
+
+	m, err := New(nil, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "first", Up: "CREATE TABLE first ();", Down: "DROP TABLE first;"},
+		{Version: 2, Name: "second", Up: "CREATE TABLE second ();", Down: "DROP TABLE second;"},
+		{Version: 10, Name: "later", Up: "CREATE TABLE later ();", Down: "DROP TABLE later;"},
+	}
+	if len(m.migrations) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d", len(m.migrations), len(want))
+	}
+	for i, w := range want {
+		if m.migrations[i] != w {
+			t.Errorf("migration %d = %+v, want %+v", i, m.migrations[i], w)
+		}
+	}
+	if got := m.Latest(); got != 10 {
+		t.Errorf("Latest = %d, want 10", got)
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"missing down", fstest.MapFS{
+			"0001_first.up.sql": file("CREATE TABLE first ();"),
+		}},
+		{"missing up", fstest.MapFS{
+			"0001_first.down.sql": file("DROP TABLE first;"),
+		}},
+		{"empty up", fstest.MapFS{
+			"0001_first.up.sql":   file(""),
+			"0001_first.down.sql": file("DROP TABLE first;"),
+		}},
+		{"conflicting names", fstest.MapFS{
+			"0001_first.up.sql":   file("CREATE TABLE first ();"),
+			"0001_other.down.sql": file("DROP TABLE first;"),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(nil, tt.fsys); err == nil {
+				t.Error("New succeeded")
+			}
+		})
+	}
+}
+
+func TestLatestWithoutMigrations(t *testing.T) {
+	m, err := New(nil, fstest.MapFS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Latest(); got != 0 {
+		t.Errorf("Latest = %d, want 0", got)
+	}
+}
+
+func TestToUnknownVersion(t *testing.T) {
+	m, err := New(nil, fstest.MapFS{
+		"0001_first.up.sql":   file("CREATE TABLE first ();"),
+		"0001_first.down.sql": file("DROP TABLE first;"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The version is checked before the database is touched
+	if _, err := m.To(2); err == nil {
+		t.Error("To an unknown version succeeded")
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := New(nil, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, mig := range m.migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions must be consecutive from 1, want %d", mig.Version, mig.Name, i+1)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/work-near-me/backend/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ApplicationRepository struct {
@@ -27,6 +28,19 @@ func (r *ApplicationRepository) FindByID(id uuid.UUID) (*domain.Application, err
 	return &app, nil
 }
 
+// FindByIDForUpdate loads an application and locks its row until the
+// surrounding transaction ends. It must be called through a UnitOfWork.
+func (r *ApplicationRepository) FindByIDForUpdate(id uuid.UUID) (*domain.Application, error) {
+	var app domain.Application
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&app).Error
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
 func (r *ApplicationRepository) FindByJobID(jobID uuid.UUID) ([]domain.Application, error) {
 	var apps []domain.Application
 	err := r.db.Preload("Worker").Where("job_id = ?", jobID).
@@ -38,6 +52,37 @@ func (r *ApplicationRepository) FindByJobID(jobID uuid.UUID) ([]domain.Applicati
 	return apps, nil
 }
 
+// FindByWorkerID returns one page of a worker's applications, newest first,
+// together with the total number matching status. An empty status matches all.
+func (r *ApplicationRepository) FindByWorkerID(workerID uuid.UUID, status domain.ApplicationStatus, offset, limit int) ([]domain.Application, int64, error) {
+	query := r.db.Model(&domain.Application{}).Where("worker_id = ?", workerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var apps []domain.Application
+	err := query.Preload("Job").Preload("Job.Employer").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&apps).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return apps, total, nil
+}
+
+func (r *ApplicationRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Application{}).Count(&count).Error
+	return count, err
+}
+
 func (r *ApplicationRepository) FindByWorkerAndJob(workerID, jobID uuid.UUID) (*domain.Application, error) {
 	var app domain.Application
 	err := r.db.Where("worker_id = ? AND job_id = ?", workerID, jobID).First(&app).Error
@@ -50,3 +95,29 @@ func (r *ApplicationRepository) FindByWorkerAndJob(workerID, jobID uuid.UUID) (*
 func (r *ApplicationRepository) Update(app *domain.Application) error {
 	return r.db.Save(app).Error
 }
+
+// RejectPendingByJobID rejects every still-pending application for the job,
+// recording reason so the rejection can be told apart from an employer decision.
+func (r *ApplicationRepository) RejectPendingByJobID(jobID uuid.UUID, reason domain.RejectionReason) error {
+	return r.RejectPendingByJobIDs([]uuid.UUID{jobID}, reason)
+}
+
+func (r *ApplicationRepository) RejectPendingByJobIDs(jobIDs []uuid.UUID, reason domain.RejectionReason) error {
+	return r.db.Model(&domain.Application{}).
+		Where("job_id IN ? AND status = ?", jobIDs, domain.ApplicationStatusPending).
+		Updates(map[string]interface{}{
+			"status":           domain.ApplicationStatusRejected,
+			"rejection_reason": reason,
+		}).Error
+}
+
+// ReopenRejectedByJobID moves applications that were rejected for reason back
+// to pending, undoing RejectPendingByJobID.
+func (r *ApplicationRepository) ReopenRejectedByJobID(jobID uuid.UUID, reason domain.RejectionReason) error {
+	return r.db.Model(&domain.Application{}).
+		Where("job_id = ? AND status = ? AND rejection_reason = ?", jobID, domain.ApplicationStatusRejected, reason).
+		Updates(map[string]interface{}{
+			"status":           domain.ApplicationStatusPending,
+			"rejection_reason": "",
+		}).Error
+}
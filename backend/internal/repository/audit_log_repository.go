@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/work-near-me/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(log *domain.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+// List returns one page of audit records, newest first, and the total
+// number of records.
+func (r *AuditLogRepository) List(offset, limit int) ([]domain.AuditLog, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.AuditLog{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []domain.AuditLog
+	err := r.db.Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/work-near-me/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AuthEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthEventRepository(db *gorm.DB) *AuthEventRepository {
+	return &AuthEventRepository{db: db}
+}
+
+func (r *AuthEventRepository) Create(event *domain.AuthEvent) error {
+	return r.db.Create(event).Error
+}
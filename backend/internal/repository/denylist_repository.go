@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// DenylistRepository holds, in Redis, the revoked sessions and the users
+// who may no longer use the API, and is checked on every authenticated
+// request. Entries only need to outlive the access tokens already issued;
+// after that, logins and refreshes are refused from the database.
+type DenylistRepository struct {
+	rdb *redis.Client
+}
+
+func NewDenylistRepository(rdb *redis.Client) *DenylistRepository {
+	return &DenylistRepository{rdb: rdb}
+}
+
+func revokedSessionKey(id uuid.UUID) string {
+	return "session:revoked:" + id.String()
+}
+
+func blockedUserKey(id uuid.UUID) string {
+	return "user:blocked:" + id.String()
+}
+
+func (r *DenylistRepository) AddSessions(ids []uuid.UUID, ttl time.Duration) error {
+	ctx := context.Background()
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			pipe.Set(ctx, revokedSessionKey(id), 1, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *DenylistRepository) BlockUser(id uuid.UUID, ttl time.Duration) error {
+	return r.rdb.Set(context.Background(), blockedUserKey(id), 1, ttl).Err()
+}
+
+func (r *DenylistRepository) UnblockUser(id uuid.UUID) error {
+	return r.rdb.Del(context.Background(), blockedUserKey(id)).Err()
+}
+
+// Denied reports whether the session or the user is denylisted, in one
+// round trip.
+func (r *DenylistRepository) Denied(sessionID, userID uuid.UUID) (bool, error) {
+	n, err := r.rdb.Exists(context.Background(), revokedSessionKey(sessionID), blockedUserKey(userID)).Result()
+	return n > 0, err
+}
@@ -1,17 +1,45 @@
 package repository
 
 import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/work-near-me/backend/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// kmPerDegreeLat is the length of one degree of latitude in kilometres.
+const kmPerDegreeLat = 111.045
+
 type JobRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	geography bool
 }
 
+// NewJobRepository checks once whether jobs has the PostGIS location column
+// added by migration 0002; nearby searches use it when present.
 func NewJobRepository(db *gorm.DB) *JobRepository {
-	return &JobRepository{db: db}
+	var geography bool
+	db.Raw(`SELECT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'jobs' AND column_name = 'location'
+	)`).Scan(&geography)
+
+	return &JobRepository{db: db, geography: geography}
+}
+
+// withDB returns a copy of the repository bound to db, typically a transaction.
+func (r *JobRepository) withDB(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db, geography: r.geography}
+}
+
+// UsesGeography reports whether nearby searches run on the PostGIS index.
+func (r *JobRepository) UsesGeography() bool {
+	return r.geography
 }
 
 func (r *JobRepository) Create(job *domain.Job) error {
@@ -27,34 +55,212 @@ func (r *JobRepository) FindByID(id uuid.UUID) (*domain.Job, error) {
 	return &job, nil
 }
 
-func (r *JobRepository) FindNearby(lat, lng, radiusKM float64) ([]domain.JobWithDistance, error) {
-	var jobs []domain.JobWithDistance
+// FindByIDForUpdate loads a job and locks its row until the surrounding
+// transaction ends. It must be called through a UnitOfWork.
+func (r *JobRepository) FindByIDForUpdate(id uuid.UUID) (*domain.Job, error) {
+	var job domain.Job
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// NearbyFilter narrows FindNearby results. Zero values are ignored.
+type NearbyFilter struct {
+	StartsAfter       time.Time
+	StartsBefore      time.Time
+	MinHourlyRate     float64
+	MinEmployerRating float64
+	Keyword           string
+}
+
+type NearbySort string
+
+const (
+	NearbySortDistance   NearbySort = "distance"
+	NearbySortHourlyRate NearbySort = "hourly_rate"
+	NearbySortNewest     NearbySort = "newest"
+	NearbySortStartTime  NearbySort = "start_time"
+)
+
+// NearbyCursor is the keyset position of the last job on a page: its sort
+// value (Value for numeric sorts, Time for time sorts) and its ID.
+type NearbyCursor struct {
+	Value float64   `json:"v"`
+	Time  time.Time `json:"t"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// NearbyPage selects one page of FindNearby results.
+type NearbyPage struct {
+	Sort  NearbySort
+	After *NearbyCursor
+	Limit int
+}
+
+// nearbyOrder describes how a NearbySort orders rows of the nearby subquery.
+type nearbyOrder struct {
+	column string
+	desc   bool
+	byTime bool
+}
+
+var nearbyOrders = map[NearbySort]nearbyOrder{
+	NearbySortDistance:   {column: "distance"},
+	NearbySortHourlyRate: {column: "hourly_rate", desc: true},
+	NearbySortNewest:     {column: "created_at", desc: true, byTime: true},
+	NearbySortStartTime:  {column: "start_time", byTime: true},
+}
+
+// FindNearby returns one page of open jobs within radiusKM of (lat, lng),
+// leaving out jobs of employers whose account is not active. It reports
+// whether more jobs follow the page.
+func (r *JobRepository) FindNearby(lat, lng, radiusKM float64, filter NearbyFilter, page NearbyPage) ([]domain.JobWithDistance, bool, error) {
+	order, ok := nearbyOrders[page.Sort]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown sort %q", page.Sort)
+	}
+
+	conditions := "status = 'open' AND employer_id NOT IN (SELECT id FROM users WHERE users.status <> 'active')"
+	var filterArgs []interface{}
+	if !filter.StartsAfter.IsZero() {
+		conditions += " AND start_time >= ?"
+		filterArgs = append(filterArgs, filter.StartsAfter)
+	}
+	if !filter.StartsBefore.IsZero() {
+		conditions += " AND start_time < ?"
+		filterArgs = append(filterArgs, filter.StartsBefore)
+	}
+	if filter.MinHourlyRate > 0 {
+		conditions += " AND hourly_rate >= ?"
+		filterArgs = append(filterArgs, filter.MinHourlyRate)
+	}
+	if filter.MinEmployerRating > 0 {
+		conditions += " AND employer_id IN (SELECT id FROM users WHERE employer_rating_avg >= ?)"
+		filterArgs = append(filterArgs, filter.MinEmployerRating)
+	}
+	if filter.Keyword != "" {
+		pattern := "%" + escapeLike(filter.Keyword) + "%"
+		conditions += " AND (title ILIKE ? OR description ILIKE ?)"
+		filterArgs = append(filterArgs, pattern, pattern)
+	}
+	if page.Sort == NearbySortStartTime {
+		// Jobs posted before scheduling existed have no place in this order
+		conditions += " AND start_time IS NOT NULL"
+	}
 
-	query := `
-		SELECT * FROM (
+	var inner string
+	var args []interface{}
+	if r.geography {
+		inner = fmt.Sprintf(`
+			SELECT jobs.*,
+				ST_Distance(location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography) / 1000 AS distance
+			FROM jobs
+			WHERE %s
+				AND ST_DWithin(location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)
+		`, conditions)
+		args = append(args, lng, lat)
+		args = append(args, filterArgs...)
+		args = append(args, lng, lat, radiusKM*1000)
+	} else {
+		// Without PostGIS, prune with a bounding box on the (latitude,
+		// longitude) index before computing the exact Haversine distance
+		minLat, maxLat, minLng, maxLng := boundingBox(lat, lng, radiusKM)
+		conditions += " AND latitude BETWEEN ? AND ?"
+		filterArgs = append(filterArgs, minLat, maxLat)
+		if minLng >= -180 && maxLng <= 180 {
+			conditions += " AND longitude BETWEEN ? AND ?"
+			filterArgs = append(filterArgs, minLng, maxLng)
+		}
+
+		inner = fmt.Sprintf(`
 			SELECT *, (
-				6371 * acos(
-					LEAST(1.0, GREATEST(-1.0,
-						cos(radians(?)) *
-						cos(radians(latitude)) *
-						cos(radians(longitude) - radians(?)) +
-						sin(radians(?)) *
-						sin(radians(latitude))
-					))
-				)
+				2 * 6371 * asin(LEAST(1.0, sqrt(
+					power(sin(radians(latitude - ?) / 2), 2) +
+					cos(radians(?)) * cos(radians(latitude)) *
+					power(sin(radians(longitude - ?) / 2), 2)
+				)))
 			) AS distance
 			FROM jobs
-			WHERE status = 'open'
-		) AS nearby
-		WHERE distance < ?
-		ORDER BY distance
-	`
+			WHERE %s
+		`, conditions)
+		args = append(args, lat, lat, lng)
+		args = append(args, filterArgs...)
+	}
 
-	err := r.db.Raw(query, lat, lng, lat, radiusKM).Scan(&jobs).Error
-	if err != nil {
-		return nil, err
+	outer := "distance < ?"
+	args = append(args, radiusKM)
+	if page.After != nil {
+		cmp := ">"
+		if order.desc {
+			cmp = "<"
+		}
+		outer += fmt.Sprintf(" AND (%s, id) %s (?, ?)", order.column, cmp)
+		if order.byTime {
+			args = append(args, page.After.Time, page.After.ID)
+		} else {
+			args = append(args, page.After.Value, page.After.ID)
+		}
 	}
-	return jobs, nil
+
+	direction := "ASC"
+	if order.desc {
+		direction = "DESC"
+	}
+	query := fmt.Sprintf(`SELECT * FROM (%s) AS nearby WHERE %s ORDER BY %s %s, id %s LIMIT ?`,
+		inner, outer, order.column, direction, direction)
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, page.Limit+1)
+
+	jobs := []domain.JobWithDistance{}
+	if err := r.db.Raw(query, args...).Scan(&jobs).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(jobs) > page.Limit
+	if hasMore {
+		jobs = jobs[:page.Limit]
+	}
+	return jobs, hasMore, nil
+}
+
+// CursorFor returns the keyset position of job under sort.
+func CursorFor(job domain.JobWithDistance, sort NearbySort) NearbyCursor {
+	cursor := NearbyCursor{ID: job.ID}
+	switch sort {
+	case NearbySortDistance:
+		cursor.Value = job.Distance
+	case NearbySortHourlyRate:
+		cursor.Value = job.HourlyRate
+	case NearbySortNewest:
+		cursor.Time = job.CreatedAt
+	case NearbySortStartTime:
+		if job.StartTime != nil {
+			cursor.Time = *job.StartTime
+		}
+	}
+	return cursor
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally inside an ILIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// boundingBox returns a latitude/longitude box containing every point within
+// radiusKM of (lat, lng).
+func boundingBox(lat, lng, radiusKM float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := radiusKM / kmPerDegreeLat
+	dLng := 180.0
+	if c := math.Cos(lat * math.Pi / 180); c > 0.01 {
+		dLng = math.Min(dLng, dLat/c)
+	}
+	return lat - dLat, lat + dLat, lng - dLng, lng + dLng
 }
 
 func (r *JobRepository) FindByEmployerID(employerID uuid.UUID) ([]domain.Job, error) {
@@ -77,6 +283,105 @@ func (r *JobRepository) FindByWorkerID(workerID uuid.UUID) ([]domain.Job, error)
 	return jobs, err
 }
 
+// JobSearch narrows Search. Query matches the title or description; empty
+// fields match everything.
+type JobSearch struct {
+	Query      string
+	Status     domain.JobStatus
+	EmployerID uuid.UUID
+}
+
+// Search returns one page of jobs matching filter, newest first, and the
+// total number of matches.
+func (r *JobRepository) Search(filter JobSearch, offset, limit int) ([]domain.Job, int64, error) {
+	query := r.db.Model(&domain.Job{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("title ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmployerID != uuid.Nil {
+		query = query.Where("employer_id = ?", filter.EmployerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []domain.Job
+	err := query.Preload("Employer").Preload("AssignedWorker").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// CountByStatus returns the number of jobs in each status.
+func (r *JobRepository) CountByStatus() (map[domain.JobStatus]int64, error) {
+	var rows []struct {
+		Status domain.JobStatus
+		Count  int64
+	}
+	err := r.db.Model(&domain.Job{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.JobStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// CountCompleted returns how many jobs the user has completed as employer
+// and as worker.
+func (r *JobRepository) CountCompleted(userID uuid.UUID) (asEmployer, asWorker int64, err error) {
+	var counts struct {
+		Employer int64
+		Worker   int64
+	}
+	err = r.db.Model(&domain.Job{}).
+		Select(`COUNT(*) FILTER (WHERE employer_id = ?) AS employer,
+			COUNT(*) FILTER (WHERE assigned_worker_id = ?) AS worker`, userID, userID).
+		Where("status = ? AND (employer_id = ? OR assigned_worker_id = ?)", domain.JobStatusDone, userID, userID).
+		Scan(&counts).Error
+	return counts.Employer, counts.Worker, err
+}
+
 func (r *JobRepository) Update(job *domain.Job) error {
 	return r.db.Save(job).Error
 }
+
+func (r *JobRepository) CreateUnassignment(u *domain.JobUnassignment) error {
+	return r.db.Create(u).Error
+}
+
+// ExpireOpen moves open jobs that were due to start before startedBefore, or
+// were posted before postedBefore, to expired and returns the IDs it changed.
+func (r *JobRepository) ExpireOpen(startedBefore, postedBefore time.Time) ([]uuid.UUID, error) {
+	var expired []domain.Job
+	err := r.db.Model(&expired).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("status = ? AND (start_time < ? OR created_at < ?)", domain.JobStatusOpen, startedBefore, postedBefore).
+		Update("status", domain.JobStatusExpired).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(expired))
+	for i := range expired {
+		ids[i] = expired[i].ID
+	}
+	return ids, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginAttemptRepository counts failed logins per phone number in Redis and
+// holds temporary lockouts.
+type LoginAttemptRepository struct {
+	rdb *redis.Client
+}
+
+func NewLoginAttemptRepository(rdb *redis.Client) *LoginAttemptRepository {
+	return &LoginAttemptRepository{rdb: rdb}
+}
+
+func loginFailuresKey(phone string) string {
+	return "login:failures:" + phone
+}
+
+func loginLockKey(phone string) string {
+	return "login:lock:" + phone
+}
+
+// LockedFor returns how long phone stays locked out, or zero.
+func (r *LoginAttemptRepository) LockedFor(phone string) (time.Duration, error) {
+	ttl, err := r.rdb.PTTL(context.Background(), loginLockKey(phone)).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// RecordFailure counts a failed login and returns the number of failures
+// since the last success. The count is forgotten after window without
+// failures.
+func (r *LoginAttemptRepository) RecordFailure(phone string, window time.Duration) (int64, error) {
+	ctx := context.Background()
+	var incr *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, loginFailuresKey(phone))
+		pipe.PExpire(ctx, loginFailuresKey(phone), window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *LoginAttemptRepository) Lock(phone string, d time.Duration) error {
+	return r.rdb.Set(context.Background(), loginLockKey(phone), 1, d).Err()
+}
+
+// Reset forgets the failures and any lockout of phone.
+func (r *LoginAttemptRepository) Reset(phone string) error {
+	return r.rdb.Del(context.Background(), loginFailuresKey(phone), loginLockKey(phone)).Err()
+}
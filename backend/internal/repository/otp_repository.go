@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/work-near-me/backend/internal/domain"
+)
+
+// OTPCheck is the outcome of checking a one-time code.
+type OTPCheck int
+
+const (
+	OTPMatched OTPCheck = iota
+	OTPMismatch
+	// OTPMissing means no code is pending: none was sent, it expired or it
+	// was already used.
+	OTPMissing
+	// OTPLocked means the attempt limit was reached and the code discarded.
+	OTPLocked
+)
+
+// reserveSendScript enforces the resend cooldown and the hourly send limit,
+// returning 0 when a send may go ahead and otherwise the milliseconds to wait.
+var reserveSendScript = redis.NewScript(`
+local wait = redis.call("PTTL", KEYS[1])
+if wait > 0 then
+	return wait
+end
+local sends = tonumber(redis.call("GET", KEYS[2]) or "0")
+if sends >= tonumber(ARGV[2]) then
+	wait = redis.call("PTTL", KEYS[2])
+	if wait > 0 then
+		return wait
+	end
+	return tonumber(ARGV[3])
+end
+redis.call("SET", KEYS[1], "1", "PX", ARGV[1])
+if redis.call("INCR", KEYS[2]) == 1 then
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+end
+return 0
+`)
+
+// checkScript counts an attempt against the pending code and deletes the
+// code once it matched or the attempt limit is reached.
+var checkScript = redis.NewScript(`
+local stored = redis.call("HGET", KEYS[1], "hash")
+if not stored then
+	return 2
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+if stored == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 0
+end
+if attempts >= tonumber(ARGV[2]) then
+	redis.call("DEL", KEYS[1])
+	return 3
+end
+return 1
+`)
+
+// sendWindow is the period MaxSends in ReserveSend applies to.
+const sendWindow = time.Hour
+
+// OTPRepository keeps pending one-time codes in Redis, hashed, keyed by
+// purpose and phone number. Codes expire on their own.
+type OTPRepository struct {
+	rdb *redis.Client
+}
+
+func NewOTPRepository(rdb *redis.Client) *OTPRepository {
+	return &OTPRepository{rdb: rdb}
+}
+
+func otpKey(kind string, purpose domain.OTPPurpose, phone string) string {
+	return fmt.Sprintf("otp:%s:%s:%s", kind, purpose, phone)
+}
+
+// ReserveSend records a send to phone unless one happened within cooldown or
+// maxSends were made in the last hour. It returns how long to wait when the
+// send is not allowed, and zero otherwise.
+func (r *OTPRepository) ReserveSend(purpose domain.OTPPurpose, phone string, cooldown time.Duration, maxSends int) (time.Duration, error) {
+	wait, err := reserveSendScript.Run(context.Background(), r.rdb,
+		[]string{otpKey("cooldown", purpose, phone), otpKey("sends", purpose, phone)},
+		cooldown.Milliseconds(), maxSends, sendWindow.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Store replaces the pending code for phone with codeHash and resets its
+// attempt count.
+func (r *OTPRepository) Store(purpose domain.OTPPurpose, phone, codeHash string, ttl time.Duration) error {
+	ctx := context.Background()
+	key := otpKey("code", purpose, phone)
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "hash", codeHash, "attempts", 0)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// Check compares codeHash with the pending code, allowing maxAttempts tries.
+func (r *OTPRepository) Check(purpose domain.OTPPurpose, phone, codeHash string, maxAttempts int) (OTPCheck, error) {
+	result, err := checkScript.Run(context.Background(), r.rdb,
+		[]string{otpKey("code", purpose, phone)},
+		codeHash, maxAttempts,
+	).Int()
+	if err != nil {
+		return OTPMissing, err
+	}
+	return OTPCheck(result), nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/work-near-me/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type RatingReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewRatingReminderRepository(db *gorm.DB) *RatingReminderRepository {
+	return &RatingReminderRepository{db: db}
+}
+
+// RecordAttempt records an attempt to send reminder at its LastAttemptAt,
+// counting it towards the attempts made so far. SentAt is set when the
+// attempt was delivered.
+func (r *RatingReminderRepository) RecordAttempt(reminder *domain.RatingReminder) error {
+	return r.db.Exec(`
+		INSERT INTO rating_reminders (job_id, user_id, attempts, last_attempt_at, sent_at)
+		VALUES (?, ?, 1, ?, ?)
+		ON CONFLICT (job_id, user_id) DO UPDATE SET
+			attempts = rating_reminders.attempts + 1,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			sent_at = EXCLUDED.sent_at
+	`, reminder.JobID, reminder.UserID, reminder.LastAttemptAt, reminder.SentAt).Error
+}
+
+// FindDue returns up to limit participants of jobs completed between
+// completedAfter and completedBefore who have neither rated the job nor been
+// reminded to. Participants whose account is not active are left out, as are
+// those with maxAttempts failed attempts and those whose last attempt was
+// less than retryDelay before now, doubled for every earlier attempt.
+func (r *RatingReminderRepository) FindDue(completedAfter, completedBefore, now time.Time, retryDelay time.Duration, maxAttempts, limit int) ([]domain.RatingReminder, error) {
+	var due []domain.RatingReminder
+	err := r.db.Raw(`
+		SELECT jobs.id AS job_id, participants.user_id
+		FROM jobs
+		CROSS JOIN LATERAL (VALUES (jobs.employer_id), (jobs.assigned_worker_id)) AS participants (user_id)
+		WHERE jobs.status = ?
+			AND jobs.completed_at > ? AND jobs.completed_at <= ?
+			AND participants.user_id IS NOT NULL
+			AND participants.user_id IN (SELECT id FROM users WHERE status = ?)
+			AND NOT EXISTS (SELECT 1 FROM ratings
+				WHERE ratings.job_id = jobs.id AND ratings.from_user_id = participants.user_id)
+			AND NOT EXISTS (SELECT 1 FROM rating_reminders
+				WHERE rating_reminders.job_id = jobs.id AND rating_reminders.user_id = participants.user_id
+					AND (rating_reminders.sent_at IS NOT NULL
+						OR rating_reminders.attempts >= ?
+						OR rating_reminders.last_attempt_at >
+							?::timestamptz - make_interval(secs => ? * power(2, rating_reminders.attempts - 1))))
+		ORDER BY jobs.completed_at
+		LIMIT ?
+	`, domain.JobStatusDone, completedAfter, completedBefore, domain.UserStatusActive,
+		maxAttempts, now, retryDelay.Seconds(), limit).Scan(&due).Error
+	return due, err
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/work-near-me/backend/internal/domain"
 	"gorm.io/gorm"
@@ -18,10 +20,30 @@ func (r *RatingRepository) Create(rating *domain.Rating) error {
 	return r.db.Create(rating).Error
 }
 
+func (r *RatingRepository) FindByID(id uuid.UUID) (*domain.Rating, error) {
+	var rating domain.Rating
+	err := r.db.Where("id = ?", id).First(&rating).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (r *RatingRepository) Delete(id uuid.UUID) error {
+	return r.db.Where("id = ?", id).Delete(&domain.Rating{}).Error
+}
+
+func (r *RatingRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Rating{}).Count(&count).Error
+	return count, err
+}
+
 func (r *RatingRepository) FindByJobID(jobID uuid.UUID) ([]domain.Rating, error) {
 	var ratings []domain.Rating
-	err := r.db.Preload("FromUser").Preload("ToUser").
+	err := r.db.Preload("Job").Preload("FromUser").Preload("ToUser").
 		Where("job_id = ?", jobID).
+		Order("created_at ASC").
 		Find(&ratings).Error
 	if err != nil {
 		return nil, err
@@ -29,7 +51,85 @@ func (r *RatingRepository) FindByJobID(jobID uuid.UUID) ([]domain.Rating, error)
 	return ratings, nil
 }
 
-func (r *RatingRepository) GetUserRatingStats(userID uuid.UUID) (float64, int, error) {
+// FindUnrevealedByJobID returns the hidden ratings of a job.
+func (r *RatingRepository) FindUnrevealedByJobID(jobID uuid.UUID) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+	err := r.db.Where("job_id = ? AND revealed_at IS NULL", jobID).Find(&ratings).Error
+	return ratings, err
+}
+
+// FindDueForReveal returns the hidden ratings of jobs completed before
+// completedBefore.
+func (r *RatingRepository) FindDueForReveal(completedBefore time.Time) ([]domain.Rating, error) {
+	var ratings []domain.Rating
+	err := r.db.
+		Where("revealed_at IS NULL AND job_id IN (SELECT id FROM jobs WHERE completed_at < ?)", completedBefore).
+		Find(&ratings).Error
+	return ratings, err
+}
+
+// Reveal makes the ratings visible as of at. Ratings already revealed keep
+// their time.
+func (r *RatingRepository) Reveal(ids []uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.Rating{}).
+		Where("id IN ? AND revealed_at IS NULL", ids).
+		Update("revealed_at", at).Error
+}
+
+// FindReceived returns one page of the revealed ratings userID received, in
+// role if it is not empty, newest first, with the title of each job and the
+// name of each rater. It also returns the total number of such ratings.
+func (r *RatingRepository) FindReceived(userID uuid.UUID, role domain.UserRole, offset, limit int) ([]domain.Rating, int64, error) {
+	query := r.db.Model(&domain.Rating{}).Where("to_user_id = ? AND revealed_at IS NOT NULL", userID)
+	if role != "" {
+		query = query.Where("to_role = ?", role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var ratings []domain.Rating
+	err := query.
+		Preload("Job", func(db *gorm.DB) *gorm.DB { return db.Select("id", "title") }).
+		Preload("FromUser", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&ratings).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return ratings, total, nil
+}
+
+// ScoreHistogram returns how many revealed ratings userID received with
+// each score, in role if it is not empty. Scores nobody gave are left out.
+func (r *RatingRepository) ScoreHistogram(userID uuid.UUID, role domain.UserRole) (map[int]int64, error) {
+	query := r.db.Model(&domain.Rating{}).Where("to_user_id = ? AND revealed_at IS NOT NULL", userID)
+	if role != "" {
+		query = query.Where("to_role = ?", role)
+	}
+
+	var rows []struct {
+		Score int
+		Count int64
+	}
+	if err := query.Select("score, COUNT(*) AS count").Group("score").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	histogram := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		histogram[row.Score] = row.Count
+	}
+	return histogram, nil
+}
+
+// GetUserRatingStats returns the average and number of the revealed ratings
+// userID received in role.
+func (r *RatingRepository) GetUserRatingStats(userID uuid.UUID, role domain.UserRole) (float64, int, error) {
 	var result struct {
 		Avg   float64
 		Count int
@@ -37,7 +137,7 @@ func (r *RatingRepository) GetUserRatingStats(userID uuid.UUID) (float64, int, e
 
 	err := r.db.Model(&domain.Rating{}).
 		Select("COALESCE(AVG(score), 0) as avg, COUNT(*) as count").
-		Where("to_user_id = ?", userID).
+		Where("to_user_id = ? AND to_role = ? AND revealed_at IS NOT NULL", userID, role).
 		Scan(&result).Error
 
 	return result.Avg, result.Count, err
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// Replace deletes every recovery code of the user and stores hashes as the
+// new set.
+func (r *RecoveryCodeRepository) Replace(userID uuid.UUID, hashes []string) error {
+	if err := r.DeleteForUser(userID); err != nil {
+		return err
+	}
+
+	codes := make([]domain.RecoveryCode, len(hashes))
+	for i, hash := range hashes {
+		codes[i] = domain.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return r.db.Create(&codes).Error
+}
+
+// Consume marks the unused code with hash as used and reports whether there
+// was one.
+func (r *RecoveryCodeRepository) Consume(userID uuid.UUID, hash string) (bool, error) {
+	result := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *RecoveryCodeRepository) CountUnused(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *RecoveryCodeRepository) DeleteForUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error
+}
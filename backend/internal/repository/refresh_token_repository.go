@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *RefreshTokenRepository) FindByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindByHashForUpdate loads a token and locks its row until the surrounding
+// transaction ends, so a token can only be rotated once.
+func (r *RefreshTokenRepository) FindByHashForUpdate(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) MarkUsed(id uuid.UUID) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("id = ?", id).
+		Update("used_at", time.Now()).Error
+}
+
+// MarkFamilyUsed marks every unused, unrevoked token of the family used, so
+// only a token issued afterwards can continue it.
+func (r *RefreshTokenRepository) MarkFamilyUsed(familyID uuid.UUID) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND used_at IS NULL AND revoked_at IS NULL", familyID).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uuid.UUID) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired removes tokens that expired before cutoff and returns how
+// many were deleted.
+func (r *RefreshTokenRepository) DeleteExpired(cutoff time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", cutoff).Delete(&domain.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
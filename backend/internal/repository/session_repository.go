@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(session *domain.Session) error {
+	return r.db.Create(session).Error
+}
+
+// FindActive returns the session with id if it belongs to userID and is
+// neither revoked nor expired.
+func (r *SessionRepository) FindActive(userID, id uuid.UUID) (*domain.Session, error) {
+	var session domain.Session
+	err := r.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", id, userID, time.Now()).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActive returns the live sessions of the user, most recently used
+// first.
+func (r *SessionRepository) ListActive(userID uuid.UUID) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records a refresh of the session from ip and userAgent.
+func (r *SessionRepository) Touch(id uuid.UUID, ip, userAgent string, expiresAt time.Time) error {
+	return r.db.Model(&domain.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ip":           ip,
+			"user_agent":   userAgent,
+			"last_used_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *SessionRepository) Revoke(id uuid.UUID) error {
+	return r.db.Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revokes every live session of the user and returns their
+// IDs.
+func (r *SessionRepository) RevokeAllForUser(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	err = r.db.Model(&domain.Session{}).
+		Where("id IN ?", ids).
+		Update("revoked_at", time.Now()).Error
+	return ids, err
+}
+
+// DeleteExpired removes sessions that expired before cutoff and returns how
+// many were deleted.
+func (r *SessionRepository) DeleteExpired(cutoff time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", cutoff).Delete(&domain.Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories groups repositories that share the same database handle,
+// so every write made through them belongs to one transaction.
+type Repositories struct {
+	Users         *UserRepository
+	Jobs          *JobRepository
+	Applications  *ApplicationRepository
+	Ratings       *RatingRepository
+	RefreshTokens *RefreshTokenRepository
+	RecoveryCodes *RecoveryCodeRepository
+	Sessions      *SessionRepository
+	AuditLogs     *AuditLogRepository
+}
+
+type UnitOfWork struct {
+	db   *gorm.DB
+	jobs *JobRepository
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db, jobs: NewJobRepository(db)}
+}
+
+// Do runs fn inside a database transaction. The transaction is committed
+// when fn returns nil and rolled back when it returns an error or panics.
+func (u *UnitOfWork) Do(fn func(repos *Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Repositories{
+			Users:         NewUserRepository(tx),
+			Jobs:          u.jobs.withDB(tx),
+			Applications:  NewApplicationRepository(tx),
+			Ratings:       NewRatingRepository(tx),
+			RefreshTokens: NewRefreshTokenRepository(tx),
+			RecoveryCodes: NewRecoveryCodeRepository(tx),
+			Sessions:      NewSessionRepository(tx),
+			AuditLogs:     NewAuditLogRepository(tx),
+		})
+	})
+}
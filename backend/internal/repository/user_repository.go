@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/work-near-me/backend/internal/domain"
 	"gorm.io/gorm"
@@ -36,15 +39,147 @@ func (r *UserRepository) FindByPhone(phone string) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+// Users are only ever updated column by column, so a flow working on a
+// stale copy of the row cannot undo a concurrent change to its other
+// columns, such as a status change or a rating aggregate.
+
+// UpdateRoles saves the roles and the active role of user.
+func (r *UserRepository) UpdateRoles(user *domain.User) error {
+	return r.db.Model(user).Select("role", "roles").Updates(user).Error
+}
+
+// UpdateTOTP saves the two-factor secret, state and last used step of user.
+func (r *UserRepository) UpdateTOTP(user *domain.User) error {
+	return r.db.Model(user).Select("totp_secret", "totp_enabled", "totp_last_step").Updates(user).Error
+}
+
+// ResetPassword sets the password hash and marks the phone number verified,
+// as only its owner can receive the reset code.
+func (r *UserRepository) ResetPassword(id uuid.UUID, hash string) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password_hash":  hash,
+			"phone_verified": true,
+		}).Error
+}
+
+// UpdateProfile saves the fields users edit on their own profile.
+func (r *UserRepository) UpdateProfile(user *domain.User) error {
+	return r.db.Model(user).Select("name", "bio", "latitude", "longitude").Updates(user).Error
 }
 
-func (r *UserRepository) UpdateRating(userID uuid.UUID, avgRating float64, count int) error {
+// ListPhones returns the ID and phone number of every user, oldest first.
+func (r *UserRepository) ListPhones() ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Select("id", "phone", "created_at").Order("created_at ASC").Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) UpdatePhone(id uuid.UUID, phone string) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Update("phone", phone).Error
+}
+
+// AdvanceTOTPStep records step as the last TOTP time step used by the user,
+// unless the same or a later step was already used. It reports whether the
+// step was new, so a code cannot be replayed even by concurrent requests.
+func (r *UserRepository) AdvanceTOTPStep(id uuid.UUID, step int64) (bool, error) {
+	result := r.db.Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *UserRepository) MarkPhoneVerified(id uuid.UUID) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Update("phone_verified", true).Error
+}
+
+// UpdateRating stores the rating aggregate of the user in role.
+func (r *UserRepository) UpdateRating(userID uuid.UUID, role domain.UserRole, avgRating float64, count int) error {
+	if role != domain.RoleEmployer && role != domain.RoleWorker {
+		return fmt.Errorf("no rating aggregate for role %q", role)
+	}
+	prefix := string(role)
 	return r.db.Model(&domain.User{}).
 		Where("id = ?", userID).
 		Updates(map[string]interface{}{
-			"rating_avg":   avgRating,
-			"rating_count": count,
+			prefix + "_rating_avg":   avgRating,
+			prefix + "_rating_count": count,
+		}).Error
+}
+
+// UserSearch narrows Search. Query matches the name or the start of the
+// phone number; empty fields match everything.
+type UserSearch struct {
+	Query  string
+	Role   domain.UserRole
+	Status domain.UserStatus
+}
+
+// Search returns one page of users matching filter, newest first, and the
+// total number of matches.
+func (r *UserRepository) Search(filter UserSearch, offset, limit int) ([]domain.User, int64, error) {
+	query := r.db.Model(&domain.User{})
+	if filter.Query != "" {
+		query = query.Where("name ILIKE ? OR phone LIKE ?", "%"+escapeLike(filter.Query)+"%", escapeLike(filter.Query)+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("? = ANY(roles)", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// SetStatus moves the user to status at the given time, recording reason.
+func (r *UserRepository) SetStatus(id uuid.UUID, status domain.UserStatus, at time.Time, reason string) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":            status,
+			"status_changed_at": at,
+			"status_reason":     reason,
 		}).Error
 }
+
+type UserCounts struct {
+	Total     int64 `json:"total"`
+	Employers int64 `json:"employers"`
+	Workers   int64 `json:"workers"`
+	Admins    int64 `json:"admins"`
+	Suspended int64 `json:"suspended"`
+	Banned    int64 `json:"banned"`
+	Deleted   int64 `json:"deleted"`
+}
+
+func (r *UserRepository) Counts() (*UserCounts, error) {
+	var counts UserCounts
+	err := r.db.Model(&domain.User{}).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE 'employer' = ANY(roles)) AS employers,
+			COUNT(*) FILTER (WHERE 'worker' = ANY(roles)) AS workers,
+			COUNT(*) FILTER (WHERE 'admin' = ANY(roles)) AS admins,
+			COUNT(*) FILTER (WHERE status = 'suspended') AS suspended,
+			COUNT(*) FILTER (WHERE status = 'banned') AS banned,
+			COUNT(*) FILTER (WHERE status = 'deleted') AS deleted`).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
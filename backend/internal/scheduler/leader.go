@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript extends the lease only while this instance still holds it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lease only while this instance still holds it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderElector holds a lease in Redis so that only one replica at a time
+// considers itself the leader. The lease expires on its own if the leader
+// dies, letting another replica take over after at most one TTL.
+type LeaderElector struct {
+	rdb    *redis.Client
+	key    string
+	id     string
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+func NewLeaderElector(rdb *redis.Client, key string, ttl time.Duration) *LeaderElector {
+	host, _ := os.Hostname()
+	return &LeaderElector{
+		rdb: rdb,
+		key: key,
+		id:  fmt.Sprintf("%s-%s", host, uuid.NewString()),
+		ttl: ttl,
+	}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns for the lease until ctx is cancelled, renewing it well
+// before it expires, and releases it on the way out.
+func (e *LeaderElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	e.campaign(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+func (e *LeaderElector) campaign(ctx context.Context) {
+	if e.leader.Load() {
+		renewed, err := renewScript.Run(ctx, e.rdb, []string{e.key}, e.id, e.ttl.Milliseconds()).Int()
+		if err != nil || renewed == 0 {
+			// Step down on any doubt; missing a run is safer than running twice
+			e.leader.Store(false)
+			log.Printf("Scheduler: lost leadership (%v)", err)
+		}
+		return
+	}
+
+	acquired, err := e.rdb.SetNX(ctx, e.key, e.id, e.ttl).Result()
+	if err != nil {
+		return
+	}
+	if acquired {
+		e.leader.Store(true)
+		log.Printf("Scheduler: acquired leadership as %s", e.id)
+	}
+}
+
+func (e *LeaderElector) release() {
+	if !e.leader.Swap(false) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = releaseScript.Run(ctx, e.rdb, []string{e.key}, e.id).Err()
+}
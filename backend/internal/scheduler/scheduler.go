@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Task is a piece of periodic housekeeping work.
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered tasks on their own intervals. Tasks only run on
+// the replica currently holding leadership, so adding replicas never makes
+// a task run more than once per interval.
+type Scheduler struct {
+	elector *LeaderElector
+	tasks   []Task
+	wg      sync.WaitGroup
+}
+
+func New(elector *LeaderElector) *Scheduler {
+	return &Scheduler{elector: elector}
+}
+
+func (s *Scheduler) Register(task Task) {
+	s.tasks = append(s.tasks, task)
+}
+
+// Start launches leader election and every registered task in the
+// background. Everything stops when ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.wg.Add(1 + len(s.tasks))
+	go func() {
+		defer s.wg.Done()
+		s.elector.Run(ctx)
+	}()
+	for _, task := range s.tasks {
+		go func(task Task) {
+			defer s.wg.Done()
+			s.loop(ctx, task)
+		}(task)
+	}
+}
+
+// Wait blocks until every task has returned and leadership was released
+// after the context passed to Start was cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, task Task) {
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.elector.IsLeader() {
+				s.run(ctx, task)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, task Task) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler: task %s panicked: %v", task.Name, r)
+		}
+	}()
+
+	if err := task.Run(ctx); err != nil {
+		log.Printf("Scheduler: task %s failed: %v", task.Name, err)
+	}
+}
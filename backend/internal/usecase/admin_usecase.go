@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrJobNotFound     = errors.New("job not found")
+	ErrRatingNotFound  = errors.New("rating not found")
+	ErrStatusUnchanged = errors.New("user already has this status")
+	ErrNotSuspended    = errors.New("user is not suspended")
+)
+
+// statusActions is the audit action recorded for a move to each status.
+var statusActions = map[domain.UserStatus]domain.AuditAction{
+	domain.UserStatusActive:    domain.AuditActionUserReinstated,
+	domain.UserStatusSuspended: domain.AuditActionUserSuspended,
+	domain.UserStatusBanned:    domain.AuditActionUserBanned,
+	domain.UserStatusDeleted:   domain.AuditActionUserDeleted,
+}
+
+// AdminUseCase serves the moderation API. Every action that changes data
+// writes an audit record in the same transaction.
+type AdminUseCase struct {
+	userRepo   *repository.UserRepository
+	jobRepo    *repository.JobRepository
+	appRepo    *repository.ApplicationRepository
+	ratingRepo *repository.RatingRepository
+	auditRepo  *repository.AuditLogRepository
+	uow        *repository.UnitOfWork
+	sessions   *SessionUseCase
+}
+
+func NewAdminUseCase(
+	userRepo *repository.UserRepository,
+	jobRepo *repository.JobRepository,
+	appRepo *repository.ApplicationRepository,
+	ratingRepo *repository.RatingRepository,
+	auditRepo *repository.AuditLogRepository,
+	uow *repository.UnitOfWork,
+	sessions *SessionUseCase,
+) *AdminUseCase {
+	return &AdminUseCase{
+		userRepo:   userRepo,
+		jobRepo:    jobRepo,
+		appRepo:    appRepo,
+		ratingRepo: ratingRepo,
+		auditRepo:  auditRepo,
+		uow:        uow,
+		sessions:   sessions,
+	}
+}
+
+type AdminUserQuery struct {
+	Query  string            `form:"q" binding:"omitempty,max=100"`
+	Role   domain.UserRole   `form:"role" binding:"omitempty,oneof=employer worker admin"`
+	Status domain.UserStatus `form:"status" binding:"omitempty,oneof=active suspended banned deleted"`
+	Page   int               `form:"page" binding:"omitempty,min=1"`
+	Limit  int               `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type UserPage struct {
+	Users []domain.User `json:"users"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int64         `json:"total"`
+}
+
+type AdminJobQuery struct {
+	Query      string           `form:"q" binding:"omitempty,max=100"`
+	Status     domain.JobStatus `form:"status" binding:"omitempty,oneof=open assigned done cancelled expired"`
+	EmployerID string           `form:"employer_id" binding:"omitempty,uuid"`
+	Page       int              `form:"page" binding:"omitempty,min=1"`
+	Limit      int              `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type JobPage struct {
+	Jobs  []domain.Job `json:"jobs"`
+	Page  int          `json:"page"`
+	Limit int          `json:"limit"`
+	Total int64        `json:"total"`
+}
+
+type AuditLogQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type AuditLogPage struct {
+	Logs  []domain.AuditLog `json:"logs"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+	Total int64             `json:"total"`
+}
+
+type SuspendUserInput struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// SetUserStatusInput requires a reason for every status but active.
+type SetUserStatusInput struct {
+	Status domain.UserStatus `json:"status" binding:"required,oneof=active suspended banned deleted"`
+	Reason string            `json:"reason" binding:"required_unless=Status active,max=500"`
+}
+
+type PlatformStats struct {
+	Users        *repository.UserCounts     `json:"users"`
+	Jobs         map[domain.JobStatus]int64 `json:"jobs"`
+	Applications int64                      `json:"applications"`
+	Ratings      int64                      `json:"ratings"`
+}
+
+// pageBounds applies the defaults of paginated admin listings.
+func pageBounds(page, limit int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func (uc *AdminUseCase) SearchUsers(query AdminUserQuery) (*UserPage, error) {
+	page, limit := pageBounds(query.Page, query.Limit)
+	filter := repository.UserSearch{
+		Query:  strings.TrimSpace(query.Query),
+		Role:   query.Role,
+		Status: query.Status,
+	}
+
+	users, total, err := uc.userRepo.Search(filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to search users")
+	}
+	return &UserPage{Users: users, Page: page, Limit: limit, Total: total}, nil
+}
+
+func (uc *AdminUseCase) SearchJobs(query AdminJobQuery) (*JobPage, error) {
+	page, limit := pageBounds(query.Page, query.Limit)
+	filter := repository.JobSearch{
+		Query:  strings.TrimSpace(query.Query),
+		Status: query.Status,
+	}
+	if query.EmployerID != "" {
+		filter.EmployerID = uuid.MustParse(query.EmployerID)
+	}
+
+	jobs, total, err := uc.jobRepo.Search(filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to search jobs")
+	}
+	return &JobPage{Jobs: jobs, Page: page, Limit: limit, Total: total}, nil
+}
+
+func (uc *AdminUseCase) AuditLogs(query AuditLogQuery) (*AuditLogPage, error) {
+	page, limit := pageBounds(query.Page, query.Limit)
+
+	logs, total, err := uc.auditRepo.List((page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to list audit logs")
+	}
+	return &AuditLogPage{Logs: logs, Page: page, Limit: limit, Total: total}, nil
+}
+
+func (uc *AdminUseCase) Stats() (*PlatformStats, error) {
+	users, err := uc.userRepo.Counts()
+	if err != nil {
+		return nil, errors.New("failed to count users")
+	}
+	jobs, err := uc.jobRepo.CountByStatus()
+	if err != nil {
+		return nil, errors.New("failed to count jobs")
+	}
+	applications, err := uc.appRepo.Count()
+	if err != nil {
+		return nil, errors.New("failed to count applications")
+	}
+	ratings, err := uc.ratingRepo.Count()
+	if err != nil {
+		return nil, errors.New("failed to count ratings")
+	}
+
+	return &PlatformStats{
+		Users:        users,
+		Jobs:         jobs,
+		Applications: applications,
+		Ratings:      ratings,
+	}, nil
+}
+
+// SetUserStatus moves the user to another status. Leaving active signs out
+// all of the user's sessions and denylists the user, so tokens already
+// issued stop working within seconds; returning to active lifts that.
+func (uc *AdminUseCase) SetUserStatus(adminID, userID uuid.UUID, input SetUserStatusInput, client ClientInfo) (*domain.User, error) {
+	if adminID == userID {
+		return nil, errors.New("you cannot change your own status")
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Status == input.Status {
+		return nil, ErrStatusUnchanged
+	}
+
+	now := time.Now()
+	var revoked []uuid.UUID
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Users.SetStatus(user.ID, input.Status, now, input.Reason); err != nil {
+			return errors.New("failed to change user status")
+		}
+
+		if input.Status != domain.UserStatusActive {
+			var err error
+			revoked, err = repos.Sessions.RevokeAllForUser(user.ID)
+			if err != nil {
+				return errors.New("failed to change user status")
+			}
+			if err := repos.RefreshTokens.RevokeAllForUser(user.ID); err != nil {
+				return errors.New("failed to change user status")
+			}
+		}
+
+		return audit(repos, adminID, statusActions[input.Status], domain.AuditTargetUser, user.ID, input.Reason, nil, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status == domain.UserStatusActive {
+		uc.sessions.unblockUser(user.ID)
+	} else {
+		uc.sessions.deny(revoked...)
+		uc.sessions.blockUser(user.ID)
+	}
+
+	user.Status = input.Status
+	user.StatusChangedAt = &now
+	user.StatusReason = input.Reason
+	return user, nil
+}
+
+// SuspendUser suspends the user, signing out all of their sessions at once.
+func (uc *AdminUseCase) SuspendUser(adminID, userID uuid.UUID, input SuspendUserInput, client ClientInfo) (*domain.User, error) {
+	return uc.SetUserStatus(adminID, userID, SetUserStatusInput{Status: domain.UserStatusSuspended, Reason: input.Reason}, client)
+}
+
+// UnsuspendUser reinstates a suspended user. Banned and deleted users are
+// reinstated through SetUserStatus only.
+func (uc *AdminUseCase) UnsuspendUser(adminID, userID uuid.UUID, client ClientInfo) (*domain.User, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Status != domain.UserStatusSuspended {
+		return nil, ErrNotSuspended
+	}
+	return uc.SetUserStatus(adminID, userID, SetUserStatusInput{Status: domain.UserStatusActive}, client)
+}
+
+// CancelJob cancels an open or assigned job on behalf of its employer.
+func (uc *AdminUseCase) CancelJob(adminID, jobID uuid.UUID, input CancelJobInput, client ClientInfo) (*domain.Job, error) {
+	var job *domain.Job
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		var err error
+		job, err = repos.Jobs.FindByIDForUpdate(jobID)
+		if err != nil {
+			return ErrJobNotFound
+		}
+
+		if err := cancelJob(repos, job, input.Reason); err != nil {
+			return err
+		}
+		return audit(repos, adminID, domain.AuditActionJobCancelled, domain.AuditTargetJob, job.ID, input.Reason, nil, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// DeleteRating removes a rating and recomputes the rated user's aggregate
+// for the role it was given in. The audit record keeps a copy of the rating.
+func (uc *AdminUseCase) DeleteRating(adminID, ratingID uuid.UUID, reason string, client ClientInfo) error {
+	return uc.uow.Do(func(repos *repository.Repositories) error {
+		rating, err := repos.Ratings.FindByID(ratingID)
+		if err != nil {
+			return ErrRatingNotFound
+		}
+
+		if err := repos.Ratings.Delete(rating.ID); err != nil {
+			return errors.New("failed to delete rating")
+		}
+
+		avg, count, err := repos.Ratings.GetUserRatingStats(rating.ToUserID, rating.ToRole)
+		if err != nil {
+			return errors.New("failed to recompute rating")
+		}
+		if err := repos.Users.UpdateRating(rating.ToUserID, rating.ToRole, avg, count); err != nil {
+			return errors.New("failed to recompute rating")
+		}
+
+		return audit(repos, adminID, domain.AuditActionRatingDeleted, domain.AuditTargetRating, rating.ID, reason, rating, client)
+	})
+}
+
+// audit writes an audit record through repos. details, when not nil, is
+// stored as JSON.
+func audit(repos *repository.Repositories, adminID uuid.UUID, action domain.AuditAction, targetType domain.AuditTargetType, targetID uuid.UUID, reason string, details interface{}, client ClientInfo) error {
+	log := &domain.AuditLog{
+		AdminID:    &adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		IP:         client.IP,
+	}
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return errors.New("failed to write audit log")
+		}
+		log.Details = string(raw)
+	}
+
+	if err := repos.AuditLogs.Create(log); err != nil {
+		return errors.New("failed to write audit log")
+	}
+	return nil
+}
@@ -8,28 +8,70 @@ import (
 	"github.com/work-near-me/backend/internal/repository"
 )
 
+// ErrJobAlreadyAssigned is returned when another worker was assigned to the
+// job before the current acceptance could take the job row lock.
+var ErrJobAlreadyAssigned = errors.New("job has already been assigned to another worker")
+
+type MyApplicationsQuery struct {
+	Status domain.ApplicationStatus `form:"status" binding:"omitempty,oneof=pending accepted rejected withdrawn"`
+	Page   int                      `form:"page" binding:"omitempty,min=1"`
+	Limit  int                      `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ApplicationPage struct {
+	Applications []domain.Application `json:"applications"`
+	Page         int                  `json:"page"`
+	Limit        int                  `json:"limit"`
+	Total        int64                `json:"total"`
+}
+
 type ApplicationUseCase struct {
 	appRepo *repository.ApplicationRepository
 	jobRepo *repository.JobRepository
+	uow     *repository.UnitOfWork
+	phones  *PhoneVerificationPolicy
 }
 
-func NewApplicationUseCase(appRepo *repository.ApplicationRepository, jobRepo *repository.JobRepository) *ApplicationUseCase {
-	return &ApplicationUseCase{appRepo: appRepo, jobRepo: jobRepo}
+func NewApplicationUseCase(
+	appRepo *repository.ApplicationRepository,
+	jobRepo *repository.JobRepository,
+	uow *repository.UnitOfWork,
+	phones *PhoneVerificationPolicy,
+) *ApplicationUseCase {
+	return &ApplicationUseCase{appRepo: appRepo, jobRepo: jobRepo, uow: uow, phones: phones}
 }
 
 func (uc *ApplicationUseCase) Apply(jobID, workerID uuid.UUID) (*domain.Application, error) {
+	if err := uc.phones.Check(workerID); err != nil {
+		return nil, err
+	}
+
 	// Check job exists and is open
 	job, err := uc.jobRepo.FindByID(jobID)
 	if err != nil {
 		return nil, errors.New("job not found")
 	}
 
-	if job.Status != domain.JobStatusOpen {
+	// Jobs of blocked employers are hidden from search; keep them closed
+	// to anyone who still has the link
+	if job.Status != domain.JobStatusOpen || (job.Employer != nil && job.Employer.Status != domain.UserStatusActive) {
 		return nil, errors.New("job is not accepting applications")
 	}
 
+	if job.EmployerID == workerID {
+		return nil, errors.New("you cannot apply to your own job")
+	}
+
 	// Check if already applied
 	existing, _ := uc.appRepo.FindByWorkerAndJob(workerID, jobID)
+	if existing != nil && existing.Status == domain.ApplicationStatusWithdrawn {
+		// A withdrawn application can be submitted again
+		existing.Status = domain.ApplicationStatusPending
+		if err := uc.appRepo.Update(existing); err != nil {
+			return nil, errors.New("failed to submit application")
+		}
+		return existing, nil
+	}
 	if existing != nil {
 		return nil, errors.New("you have already applied for this job")
 	}
@@ -62,17 +104,50 @@ func (uc *ApplicationUseCase) Accept(appID, employerID uuid.UUID) (*domain.Appli
 		return nil, errors.New("application is not pending")
 	}
 
-	app.Status = domain.ApplicationStatusAccepted
-	if err := uc.appRepo.Update(app); err != nil {
-		return nil, errors.New("failed to accept application")
-	}
-
-	// Also assign the worker to the job
-	job := app.Job
-	job.Status = domain.JobStatusAssigned
-	job.AssignedWorkerID = &app.WorkerID
-	if err := uc.jobRepo.Update(job); err != nil {
-		return nil, errors.New("failed to assign worker to job")
+	// The job row lock serializes concurrent acceptances for the same job,
+	// so the status checks below are authoritative.
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		job, err := repos.Jobs.FindByIDForUpdate(app.JobID)
+		if err != nil {
+			return errors.New("job not found")
+		}
+		if job.Status == domain.JobStatusAssigned {
+			return ErrJobAlreadyAssigned
+		}
+		if job.Status != domain.JobStatusOpen {
+			return errors.New("job is not open for assignment")
+		}
+
+		locked, err := repos.Applications.FindByIDForUpdate(app.ID)
+		if err != nil {
+			return errors.New("application not found")
+		}
+		if locked.Status != domain.ApplicationStatusPending {
+			return errors.New("application is not pending")
+		}
+
+		locked.Status = domain.ApplicationStatusAccepted
+		if err := repos.Applications.Update(locked); err != nil {
+			return errors.New("failed to accept application")
+		}
+
+		// Also assign the worker to the job
+		job.Status = domain.JobStatusAssigned
+		job.AssignedWorkerID = &locked.WorkerID
+		if err := repos.Jobs.Update(job); err != nil {
+			return errors.New("failed to assign worker to job")
+		}
+
+		if err := repos.Applications.RejectPendingByJobID(job.ID, domain.RejectionReasonFilled); err != nil {
+			return errors.New("failed to reject remaining applications")
+		}
+
+		app.Status = locked.Status
+		app.Job = job
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return app, nil
@@ -93,6 +168,7 @@ func (uc *ApplicationUseCase) Reject(appID, employerID uuid.UUID) (*domain.Appli
 	}
 
 	app.Status = domain.ApplicationStatusRejected
+	app.RejectionReason = domain.RejectionReasonDeclined
 	if err := uc.appRepo.Update(app); err != nil {
 		return nil, errors.New("failed to reject application")
 	}
@@ -103,3 +179,59 @@ func (uc *ApplicationUseCase) Reject(appID, employerID uuid.UUID) (*domain.Appli
 func (uc *ApplicationUseCase) GetByJobID(jobID uuid.UUID) ([]domain.Application, error) {
 	return uc.appRepo.FindByJobID(jobID)
 }
+
+func (uc *ApplicationUseCase) Withdraw(appID, workerID uuid.UUID) (*domain.Application, error) {
+	var app *domain.Application
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		var err error
+		app, err = repos.Applications.FindByIDForUpdate(appID)
+		if err != nil {
+			return errors.New("application not found")
+		}
+
+		if app.WorkerID != workerID {
+			return errors.New("only the applicant can withdraw the application")
+		}
+
+		if app.Status == domain.ApplicationStatusAccepted {
+			return errors.New("application was accepted, unassign from the job instead")
+		}
+		if app.Status != domain.ApplicationStatusPending {
+			return errors.New("application is not pending")
+		}
+
+		app.Status = domain.ApplicationStatusWithdrawn
+		if err := repos.Applications.Update(app); err != nil {
+			return errors.New("failed to withdraw application")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (uc *ApplicationUseCase) GetByWorkerID(workerID uuid.UUID, query MyApplicationsQuery) (*ApplicationPage, error) {
+	page := query.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	apps, total, err := uc.appRepo.FindByWorkerID(workerID, query.Status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApplicationPage{
+		Applications: apps,
+		Page:         page,
+		Limit:        limit,
+		Total:        total,
+	}, nil
+}
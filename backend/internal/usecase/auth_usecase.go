@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/work-near-me/backend/config"
@@ -11,14 +13,58 @@ import (
 )
 
 type AuthUseCase struct {
-	userRepo *repository.UserRepository
-	cfg      *config.Config
+	userRepo  *repository.UserRepository
+	tokenRepo *repository.RefreshTokenRepository
+	eventRepo *repository.AuthEventRepository
+	uow       *repository.UnitOfWork
+	tokens    *pkg.TokenIssuer
+	otp       *OTPService
+	guard     *LoginGuard
+	twoFactor *TwoFactorUseCase
+	sessions  *SessionUseCase
+	cfg       *config.Config
 }
 
-func NewAuthUseCase(userRepo *repository.UserRepository, cfg *config.Config) *AuthUseCase {
-	return &AuthUseCase{userRepo: userRepo, cfg: cfg}
+func NewAuthUseCase(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.RefreshTokenRepository,
+	eventRepo *repository.AuthEventRepository,
+	uow *repository.UnitOfWork,
+	tokens *pkg.TokenIssuer,
+	otp *OTPService,
+	guard *LoginGuard,
+	twoFactor *TwoFactorUseCase,
+	sessions *SessionUseCase,
+	cfg *config.Config,
+) *AuthUseCase {
+	return &AuthUseCase{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		eventRepo: eventRepo,
+		uow:       uow,
+		tokens:    tokens,
+		otp:       otp,
+		guard:     guard,
+		twoFactor: twoFactor,
+		sessions:  sessions,
+		cfg:       cfg,
+	}
 }
 
+var (
+	ErrRoleNotHeld     = errors.New("your account does not have this role")
+	ErrRoleAlreadyHeld = errors.New("your account already has this role")
+	// ErrAccountSuspended and ErrAccountBanned are only returned once the
+	// password is correct, so they do not reveal which numbers are blocked.
+	ErrAccountSuspended = errors.New("your account has been suspended")
+	ErrAccountBanned    = errors.New("your account has been banned")
+)
+
+const (
+	phoneVerificationMessage = "Your ShortJob verification code is %s. It expires in %d minutes."
+	passwordResetMessage     = "Your ShortJob password reset code is %s. It expires in %d minutes. If you did not ask to reset your password, ignore this message."
+)
+
 type RegisterInput struct {
 	Name     string          `json:"name" binding:"required"`
 	Phone    string          `json:"phone" binding:"required"`
@@ -26,6 +72,15 @@ type RegisterInput struct {
 	Role     domain.UserRole `json:"role" binding:"required,oneof=employer worker"`
 }
 
+type RoleInput struct {
+	Role domain.UserRole `json:"role" binding:"required,oneof=employer worker admin"`
+}
+
+// AddRoleInput leaves out admin, which users cannot give themselves.
+type AddRoleInput struct {
+	Role domain.UserRole `json:"role" binding:"required,oneof=employer worker"`
+}
+
 type LoginInput struct {
 	Phone    string `json:"phone" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -35,15 +90,44 @@ type RefreshInput struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type VerifyPhoneInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type ResetPasswordInput struct {
+	Phone       string `json:"phone" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+type MFALoginInput struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code is a TOTP code or a recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+// AuthResponse carries a token pair, or for accounts with two-factor
+// authentication a challenge token to pass to LoginMFA with a second factor.
 type AuthResponse struct {
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	User         *domain.User `json:"user"`
+	AccessToken  string       `json:"access_token,omitempty"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	User         *domain.User `json:"user,omitempty"`
+	MFARequired  bool         `json:"mfa_required,omitempty"`
+	MFAToken     string       `json:"mfa_token,omitempty"`
 }
 
-func (uc *AuthUseCase) Register(input RegisterInput) (*AuthResponse, error) {
+func (uc *AuthUseCase) Register(input RegisterInput, client ClientInfo) (*AuthResponse, error) {
+	phone, err := pkg.NormalizePhone(input.Phone)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if phone already exists
-	existing, _ := uc.userRepo.FindByPhone(input.Phone)
+	existing, _ := uc.userRepo.FindByPhone(phone)
 	if existing != nil {
 		return nil, errors.New("phone number already registered")
 	}
@@ -56,60 +140,483 @@ func (uc *AuthUseCase) Register(input RegisterInput) (*AuthResponse, error) {
 
 	user := &domain.User{
 		Name:         input.Name,
-		Phone:        input.Phone,
+		Phone:        phone,
 		PasswordHash: hash,
 		Role:         input.Role,
+		Roles:        domain.UserRoles{input.Role},
+		Status:       domain.UserStatusActive,
 	}
 
 	if err := uc.userRepo.Create(user); err != nil {
 		return nil, errors.New("failed to create user")
 	}
 
-	return uc.generateTokens(user)
+	return uc.startSession(user, client)
 }
 
-func (uc *AuthUseCase) Login(input LoginInput) (*AuthResponse, error) {
-	user, err := uc.userRepo.FindByPhone(input.Phone)
+// Login checks the password of the account registered for the phone number.
+// Failures are counted per number, and a number with too many recent
+// failures is locked out regardless of which IP the attempts come from.
+func (uc *AuthUseCase) Login(input LoginInput, client ClientInfo) (*AuthResponse, error) {
+	phone, err := pkg.NormalizePhone(input.Phone)
 	if err != nil {
 		return nil, errors.New("invalid phone or password")
 	}
 
-	if !pkg.CheckPassword(input.Password, user.PasswordHash) {
+	user, _ := uc.userRepo.FindByPhone(phone)
+
+	if err := uc.guard.Allow(phone); err != nil {
+		uc.recordEvent(domain.AuthEventLoginBlocked, phone, user, client)
+		return nil, err
+	}
+
+	if user == nil || !pkg.CheckPassword(input.Password, user.PasswordHash) {
+		uc.recordEvent(domain.AuthEventLoginFailed, phone, user, client)
+		if lockout := uc.guard.Failed(phone, user); lockout > 0 {
+			uc.recordEvent(domain.AuthEventAccountLocked, phone, user, client)
+		}
 		return nil, errors.New("invalid phone or password")
 	}
 
-	return uc.generateTokens(user)
+	if err := statusError(user); err != nil {
+		uc.recordEvent(domain.AuthEventLoginBlocked, phone, user, client)
+		return nil, err
+	}
+
+	// With two-factor authentication the password alone does not clear the
+	// failure count, or the second factor could be guessed without limit
+	// by logging in again after each few wrong codes
+	if user.TOTPEnabled {
+		token, err := uc.tokens.GenerateMFAToken(user.ID, uc.cfg.JWT.MFAExpiry)
+		if err != nil {
+			return nil, errors.New("failed to start two-factor login")
+		}
+		return &AuthResponse{MFARequired: true, MFAToken: token}, nil
+	}
+
+	uc.guard.Succeeded(phone)
+	uc.recordEvent(domain.AuthEventLoginSucceeded, phone, user, client)
+
+	return uc.startSession(user, client)
 }
 
-func (uc *AuthUseCase) Refresh(input RefreshInput) (*AuthResponse, error) {
-	userID, err := pkg.ValidateRefreshToken(input.RefreshToken, uc.cfg.JWT.Secret)
+// LoginMFA completes a login challenged for a second factor. Wrong codes
+// count towards the same per-phone lockout as wrong passwords.
+func (uc *AuthUseCase) LoginMFA(input MFALoginInput, client ClientInfo) (*AuthResponse, error) {
+	claims, err := uc.tokens.ValidateMFAToken(input.MFAToken)
 	if err != nil {
+		return nil, errors.New("login challenge is invalid or has expired, please log in again")
+	}
+
+	user, err := uc.userRepo.FindByID(claims.UserID())
+	if err != nil || !user.TOTPEnabled {
+		return nil, errors.New("login challenge is invalid or has expired, please log in again")
+	}
+	if err := statusError(user); err != nil {
+		return nil, err
+	}
+
+	if err := uc.guard.Allow(user.Phone); err != nil {
+		uc.recordEvent(domain.AuthEventLoginBlocked, user.Phone, user, client)
+		return nil, err
+	}
+
+	if err := uc.twoFactor.Verify(user, input.Code); err != nil {
+		uc.recordEvent(domain.AuthEventMFAFailed, user.Phone, user, client)
+		if lockout := uc.guard.Failed(user.Phone, user); lockout > 0 {
+			uc.recordEvent(domain.AuthEventAccountLocked, user.Phone, user, client)
+		}
+		return nil, err
+	}
+
+	uc.guard.Succeeded(user.Phone)
+	uc.recordEvent(domain.AuthEventLoginSucceeded, user.Phone, user, client)
+
+	return uc.startSession(user, client)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// works once; presenting one that was already used is treated as theft and
+// revokes its session. client is recorded as the session's latest device.
+func (uc *AuthUseCase) Refresh(input RefreshInput, client ClientInfo) (*AuthResponse, error) {
+	if _, err := uc.tokens.ValidateRefreshToken(input.RefreshToken); err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
 
+	var resp *AuthResponse
+	var reused *domain.RefreshToken
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		stored, err := repos.RefreshTokens.FindByHashForUpdate(pkg.HashToken(input.RefreshToken))
+		if err != nil {
+			return errors.New("invalid refresh token")
+		}
+		if stored.RevokedAt != nil {
+			return errors.New("refresh token has been revoked")
+		}
+		if stored.UsedAt != nil {
+			// Commit the revocation, then report the failure below
+			reused = stored
+			if err := repos.Sessions.Revoke(stored.FamilyID); err != nil {
+				return err
+			}
+			return repos.RefreshTokens.RevokeFamily(stored.FamilyID)
+		}
+
+		user, err := repos.Users.FindByID(stored.UserID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		if err := statusError(user); err != nil {
+			return err
+		}
+
+		if err := repos.RefreshTokens.MarkUsed(stored.ID); err != nil {
+			return errors.New("failed to rotate refresh token")
+		}
+		expiresAt := time.Now().Add(uc.cfg.JWT.RefreshExpiry)
+		if err := repos.Sessions.Touch(stored.FamilyID, client.IP, truncate(client.UserAgent, 255), expiresAt); err != nil {
+			return errors.New("failed to rotate refresh token")
+		}
+
+		resp, err = uc.generateTokens(repos.RefreshTokens, user, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused != nil {
+		uc.sessions.deny(reused.FamilyID)
+		return nil, errors.New("refresh token reuse detected, please log in again")
+	}
+
+	return resp, nil
+}
+
+// Logout ends the session of the refresh token, revoking every token
+// rotated from the same login.
+func (uc *AuthUseCase) Logout(input RefreshInput) error {
+	claims, err := uc.tokens.ValidateRefreshToken(input.RefreshToken)
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	stored, err := uc.tokenRepo.FindByHash(pkg.HashToken(input.RefreshToken))
+	if err != nil || stored.UserID != claims.UserID() {
+		return errors.New("invalid refresh token")
+	}
+
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Sessions.Revoke(stored.FamilyID); err != nil {
+			return errors.New("failed to log out")
+		}
+		if err := repos.RefreshTokens.RevokeFamily(stored.FamilyID); err != nil {
+			return errors.New("failed to log out")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.sessions.deny(stored.FamilyID)
+	return nil
+}
+
+// LogoutAll revokes every session and refresh token of the user, signing
+// out all devices.
+func (uc *AuthUseCase) LogoutAll(userID uuid.UUID) error {
+	var revoked []uuid.UUID
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		var err error
+		revoked, err = repos.Sessions.RevokeAllForUser(userID)
+		if err != nil {
+			return errors.New("failed to log out")
+		}
+		if err := repos.RefreshTokens.RevokeAllForUser(userID); err != nil {
+			return errors.New("failed to log out")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.sessions.deny(revoked...)
+	return nil
+}
+
+// PurgeExpiredTokens deletes refresh token records past their expiry; their
+// JWTs no longer validate, so the records cannot matter any more.
+func (uc *AuthUseCase) PurgeExpiredTokens() (int64, error) {
+	return uc.tokenRepo.DeleteExpired(time.Now())
+}
+
+// SwitchRole makes role, which the user must hold, the active role and
+// issues a token pair for it in the current session. The active role belongs
+// to the account, so other sessions pick it up at their next refresh.
+func (uc *AuthUseCase) SwitchRole(userID, sessionID uuid.UUID, input RoleInput) (*AuthResponse, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.Roles.Has(input.Role) {
+		return nil, ErrRoleNotHeld
+	}
+
+	user.Role = input.Role
+	return uc.reissueTokens(user, sessionID)
+}
+
+// AddRole gives the user another role and switches to it, so one account
+// can both hire and work.
+func (uc *AuthUseCase) AddRole(userID, sessionID uuid.UUID, input AddRoleInput) (*AuthResponse, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Roles.Has(input.Role) {
+		return nil, ErrRoleAlreadyHeld
+	}
+
+	user.Roles = append(user.Roles, input.Role)
+	user.Role = input.Role
+	return uc.reissueTokens(user, sessionID)
+}
+
+// reissueTokens saves a change to the user's roles and issues a token pair
+// carrying it in sessionID. The session's outstanding refresh token is used
+// up, as by a refresh, so the family keeps a single valid token and
+// presenting the old one counts as reuse.
+func (uc *AuthUseCase) reissueTokens(user *domain.User, sessionID uuid.UUID) (*AuthResponse, error) {
+	if sessionID == uuid.Nil {
+		return nil, errors.New("session not found, please log in again")
+	}
+
+	var resp *AuthResponse
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		// The access token alone does not prove the session is still live,
+		// and issuing a new refresh token would bring a revoked one back
+		if _, err := repos.Sessions.FindActive(user.ID, sessionID); err != nil {
+			return errors.New("session not found, please log in again")
+		}
+
+		if err := repos.Users.UpdateRoles(user); err != nil {
+			return errors.New("failed to update roles")
+		}
+
+		if err := repos.RefreshTokens.MarkFamilyUsed(sessionID); err != nil {
+			return errors.New("failed to rotate refresh token")
+		}
+
+		var err error
+		resp, err = uc.generateTokens(repos.RefreshTokens, user, sessionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SendPhoneCode texts a verification code to the user's phone number. It
+// also serves resends, which replace the pending code once the cooldown has
+// passed.
+func (uc *AuthUseCase) SendPhoneCode(userID uuid.UUID) (*OTPSent, error) {
 	user, err := uc.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
+	if user.PhoneVerified {
+		return nil, ErrPhoneAlreadyVerified
+	}
 
-	return uc.generateTokens(user)
+	return uc.otp.Send(domain.OTPPurposePhoneVerification, user.Phone, phoneVerificationMessage)
 }
 
-func (uc *AuthUseCase) generateTokens(user *domain.User) (*AuthResponse, error) {
-	accessToken, err := pkg.GenerateAccessToken(
-		user.ID, string(user.Role), uc.cfg.JWT.Secret, uc.cfg.JWT.AccessExpiry,
-	)
+// VerifyPhone marks the user's phone number verified when the code matches
+// the one last sent to it.
+func (uc *AuthUseCase) VerifyPhone(userID uuid.UUID, input VerifyPhoneInput) (*domain.User, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.PhoneVerified {
+		return nil, ErrPhoneAlreadyVerified
+	}
+
+	if err := uc.otp.Check(domain.OTPPurposePhoneVerification, user.Phone, input.Code); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.MarkPhoneVerified(user.ID); err != nil {
+		return nil, errors.New("failed to verify phone number")
+	}
+	user.PhoneVerified = true
+	return user, nil
+}
+
+// ForgotPassword texts a password reset code to a registered phone number.
+// The answer is the same whether or not the number is registered.
+func (uc *AuthUseCase) ForgotPassword(input ForgotPasswordInput) (*OTPSent, error) {
+	phone, err := pkg.NormalizePhone(input.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.userRepo.FindByPhone(phone); err != nil {
+		return uc.otp.Throttle(domain.OTPPurposePasswordReset, phone)
+	}
+	return uc.otp.Send(domain.OTPPurposePasswordReset, phone, passwordResetMessage)
+}
+
+// ResetPassword sets a new password when the reset code matches, and signs
+// the user out everywhere by revoking all of their sessions. Receiving
+// the code also proves the phone number, so it is marked verified.
+func (uc *AuthUseCase) ResetPassword(input ResetPasswordInput, client ClientInfo) error {
+	phone, err := pkg.NormalizePhone(input.Phone)
+	if err != nil {
+		return ErrInvalidOTP
+	}
+
+	if err := uc.otp.Check(domain.OTPPurposePasswordReset, phone, input.Code); err != nil {
+		return err
+	}
+
+	hash, err := pkg.HashPassword(input.NewPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	var user *domain.User
+	var revoked []uuid.UUID
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		user, err = repos.Users.FindByPhone(phone)
+		if err != nil {
+			return ErrInvalidOTP
+		}
+
+		user.PasswordHash = hash
+		user.PhoneVerified = true
+		if err := repos.Users.ResetPassword(user.ID, hash); err != nil {
+			return errors.New("failed to reset password")
+		}
+
+		revoked, err = repos.Sessions.RevokeAllForUser(user.ID)
+		if err != nil {
+			return errors.New("failed to reset password")
+		}
+		if err := repos.RefreshTokens.RevokeAllForUser(user.ID); err != nil {
+			return errors.New("failed to reset password")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	uc.sessions.deny(revoked...)
+
+	// The owner proved control of the number, so lift any login lockout
+	uc.guard.Succeeded(phone)
+	uc.recordEvent(domain.AuthEventPasswordReset, phone, user, client)
+	return nil
+}
+
+func (uc *AuthUseCase) recordEvent(typ domain.AuthEventType, phone string, user *domain.User, client ClientInfo) {
+	recordAuthEvent(uc.eventRepo, typ, phone, user, client)
+}
+
+// recordAuthEvent writes an audit record. Failing to write one does not fail
+// the request that caused it.
+func recordAuthEvent(repo *repository.AuthEventRepository, typ domain.AuthEventType, phone string, user *domain.User, client ClientInfo) {
+	event := &domain.AuthEvent{
+		Phone:     phone,
+		Type:      typ,
+		IP:        client.IP,
+		UserAgent: truncate(client.UserAgent, 255),
+	}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	if err := repo.Create(event); err != nil {
+		log.Printf("Auth: failed to record %s event: %v", typ, err)
+	}
+}
+
+// statusError returns why user may not log in or refresh, or nil for an
+// active account. Deleted accounts fail like unknown ones.
+func statusError(user *domain.User) error {
+	switch user.Status {
+	case domain.UserStatusActive:
+		return nil
+	case domain.UserStatusSuspended:
+		return ErrAccountSuspended
+	case domain.UserStatusBanned:
+		return ErrAccountBanned
+	default:
+		return errors.New("invalid phone or password")
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// startSession records a new session for a login from client and issues
+// its first token pair.
+func (uc *AuthUseCase) startSession(user *domain.User, client ClientInfo) (*AuthResponse, error) {
+	now := time.Now()
+	session := &domain.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IP:         client.IP,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(uc.cfg.JWT.RefreshExpiry),
+	}
+
+	var resp *AuthResponse
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Sessions.Create(session); err != nil {
+			return errors.New("failed to start session")
+		}
+
+		var err error
+		resp, err = uc.generateTokens(repos.RefreshTokens, user, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// generateTokens issues an access token and a refresh token for the session
+// familyID, recording the refresh token through tokens.
+func (uc *AuthUseCase) generateTokens(tokens *repository.RefreshTokenRepository, user *domain.User, familyID uuid.UUID) (*AuthResponse, error) {
+	accessToken, err := uc.tokens.GenerateAccessToken(user.ID, familyID, string(user.Role), uc.cfg.JWT.AccessExpiry)
 	if err != nil {
 		return nil, errors.New("failed to generate access token")
 	}
 
-	refreshToken, err := pkg.GenerateRefreshToken(
-		user.ID, uc.cfg.JWT.Secret, uc.cfg.JWT.RefreshExpiry,
-	)
+	tokenID := uuid.New()
+	refreshToken, err := uc.tokens.GenerateRefreshToken(user.ID, tokenID, familyID, uc.cfg.JWT.RefreshExpiry)
 	if err != nil {
 		return nil, errors.New("failed to generate refresh token")
 	}
 
+	if err := tokens.Create(&domain.RefreshToken{
+		ID:        tokenID,
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: pkg.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(uc.cfg.JWT.RefreshExpiry),
+	}); err != nil {
+		return nil, errors.New("failed to store refresh token")
+	}
+
 	return &AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
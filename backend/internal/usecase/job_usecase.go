@@ -1,7 +1,12 @@
 package usecase
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/work-near-me/backend/config"
@@ -12,46 +17,214 @@ import (
 type JobUseCase struct {
 	jobRepo    *repository.JobRepository
 	ratingRepo *repository.RatingRepository
+	uow        *repository.UnitOfWork
+	phones     *PhoneVerificationPolicy
 	cfg        *config.Config
 }
 
 func NewJobUseCase(
 	jobRepo *repository.JobRepository,
 	ratingRepo *repository.RatingRepository,
+	uow *repository.UnitOfWork,
+	phones *PhoneVerificationPolicy,
 	cfg *config.Config,
 ) *JobUseCase {
 	return &JobUseCase{
 		jobRepo:    jobRepo,
 		ratingRepo: ratingRepo,
+		uow:        uow,
+		phones:     phones,
 		cfg:        cfg,
 	}
 }
 
+const (
+	// maxJobDuration caps how long a single short-term job may run
+	maxJobDuration = 7 * 24 * time.Hour
+	// startTimeGrace tolerates a start time slightly in the past, e.g. a job
+	// posted for "now" that took a few minutes to fill in
+	startTimeGrace = 15 * time.Minute
+)
+
+// jobTimeLayouts are accepted for start and end times without a UTC offset;
+// such values are read in the job's timezone.
+var jobTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
 type CreateJobInput struct {
-	Title        string  `json:"title" binding:"required"`
-	Description  string  `json:"description"`
-	HourlyRate   float64 `json:"hourly_rate" binding:"required,gt=0"`
-	TotalPayment float64 `json:"total_payment"`
-	Latitude     float64 `json:"latitude" binding:"required"`
-	Longitude    float64 `json:"longitude" binding:"required"`
+	Title          string  `json:"title" binding:"required"`
+	Description    string  `json:"description"`
+	HourlyRate     float64 `json:"hourly_rate" binding:"required,gt=0"`
+	TotalPayment   float64 `json:"total_payment"`
+	Latitude       float64 `json:"latitude" binding:"required"`
+	Longitude      float64 `json:"longitude" binding:"required"`
+	StartTime      string  `json:"start_time" binding:"required"`
+	EndTime        string  `json:"end_time" binding:"required"`
+	Timezone       string  `json:"timezone"`
+	EstimatedHours float64 `json:"estimated_hours" binding:"omitempty,gt=0"`
+}
+
+type jobSchedule struct {
+	start    time.Time
+	end      time.Time
+	timezone string
+	hours    float64
+}
+
+// schedule parses and validates the start and end times, falling back to
+// defaultTimezone when the input does not name one.
+func (in CreateJobInput) schedule(defaultTimezone string, now time.Time) (*jobSchedule, error) {
+	tz := in.Timezone
+	if tz == "" {
+		tz = defaultTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, errors.New("invalid timezone")
+	}
+
+	start, err := parseJobTime(in.StartTime, loc)
+	if err != nil {
+		return nil, errors.New("invalid start_time")
+	}
+	end, err := parseJobTime(in.EndTime, loc)
+	if err != nil {
+		return nil, errors.New("invalid end_time")
+	}
+
+	if start.Before(now.Add(-startTimeGrace)) {
+		return nil, errors.New("start_time must not be in the past")
+	}
+	if !end.After(start) {
+		return nil, errors.New("end_time must be after start_time")
+	}
+	if end.Sub(start) > maxJobDuration {
+		return nil, errors.New("a job cannot last longer than 7 days")
+	}
+
+	hours := in.EstimatedHours
+	if hours == 0 {
+		hours = end.Sub(start).Hours()
+	}
+	if hours > end.Sub(start).Hours() {
+		return nil, errors.New("estimated_hours cannot exceed the time between start_time and end_time")
+	}
+
+	return &jobSchedule{
+		start:    start.In(loc),
+		end:      end.In(loc),
+		timezone: tz,
+		hours:    hours,
+	}, nil
+}
+
+func parseJobTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range jobTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognized time format")
+}
+
+type CancelJobInput struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type UnassignInput struct {
+	Reason string `json:"reason" binding:"max=500"`
 }
 
+const (
+	defaultNearbyLimit = 20
+	maxNearbyLimit     = 50
+)
+
+// ErrInvalidCursor is returned when a nearby cursor cannot be decoded or was
+// issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type NearbyQuery struct {
 	Latitude  float64 `form:"lat" binding:"required"`
 	Longitude float64 `form:"lng" binding:"required"`
 	RadiusKM  float64 `form:"radius"`
+	// StartsWithinHours keeps jobs starting between now and now + N hours,
+	// up to a year ahead
+	StartsWithinHours float64 `form:"starts_within_hours" binding:"omitempty,gt=0,max=8760"`
+	// TodayOnly keeps jobs starting later today in the default timezone
+	TodayOnly         bool    `form:"today"`
+	MinHourlyRate     float64 `form:"min_hourly_rate" binding:"omitempty,gte=0"`
+	MinEmployerRating float64 `form:"min_employer_rating" binding:"omitempty,gte=0,lte=5"`
+	Keyword           string  `form:"q" binding:"omitempty,max=100"`
+	Sort              string  `form:"sort" binding:"omitempty,oneof=distance hourly_rate newest start_time"`
+	Cursor            string  `form:"cursor"`
+	Limit             int     `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type NearbyJobsPage struct {
+	Jobs       []domain.JobWithDistance `json:"jobs"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+}
+
+// nearbyCursor is the opaque value handed to clients as next_cursor.
+type nearbyCursor struct {
+	Sort repository.NearbySort   `json:"s"`
+	At   repository.NearbyCursor `json:"at"`
+}
+
+func encodeNearbyCursor(sort repository.NearbySort, at repository.NearbyCursor) string {
+	raw, _ := json.Marshal(nearbyCursor{Sort: sort, At: at})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeNearbyCursor(value string, sort repository.NearbySort) (*repository.NearbyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor nearbyCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor.At, nil
 }
 
 func (uc *JobUseCase) Create(employerID uuid.UUID, input CreateJobInput) (*domain.Job, error) {
+	if err := uc.phones.Check(employerID); err != nil {
+		return nil, err
+	}
+
+	schedule, err := input.schedule(uc.cfg.App.DefaultTimezone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the total from the rate when the employer leaves it out
+	totalPayment := input.TotalPayment
+	if totalPayment <= 0 {
+		totalPayment = math.Round(input.HourlyRate * schedule.hours)
+	}
+
 	job := &domain.Job{
-		EmployerID:   employerID,
-		Title:        input.Title,
-		Description:  input.Description,
-		HourlyRate:   input.HourlyRate,
-		TotalPayment: input.TotalPayment,
-		Latitude:     input.Latitude,
-		Longitude:    input.Longitude,
-		Status:       domain.JobStatusOpen,
+		EmployerID:     employerID,
+		Title:          input.Title,
+		Description:    input.Description,
+		HourlyRate:     input.HourlyRate,
+		TotalPayment:   totalPayment,
+		StartTime:      &schedule.start,
+		EndTime:        &schedule.end,
+		Timezone:       schedule.timezone,
+		EstimatedHours: schedule.hours,
+		Latitude:       input.Latitude,
+		Longitude:      input.Longitude,
+		Status:         domain.JobStatusOpen,
 	}
 
 	if err := uc.jobRepo.Create(job); err != nil {
@@ -61,7 +234,7 @@ func (uc *JobUseCase) Create(employerID uuid.UUID, input CreateJobInput) (*domai
 	return job, nil
 }
 
-func (uc *JobUseCase) GetNearby(query NearbyQuery) ([]domain.JobWithDistance, error) {
+func (uc *JobUseCase) GetNearby(query NearbyQuery) (*NearbyJobsPage, error) {
 	radius := query.RadiusKM
 	if radius <= 0 {
 		radius = 3 // default 3km
@@ -70,7 +243,66 @@ func (uc *JobUseCase) GetNearby(query NearbyQuery) ([]domain.JobWithDistance, er
 		radius = uc.cfg.App.MaxSearchRadiusKM
 	}
 
-	return uc.jobRepo.FindNearby(query.Latitude, query.Longitude, radius)
+	filter, err := uc.nearbyFilter(query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	page := repository.NearbyPage{
+		Sort:  repository.NearbySortDistance,
+		Limit: query.Limit,
+	}
+	if query.Sort != "" {
+		page.Sort = repository.NearbySort(query.Sort)
+	}
+	if page.Limit <= 0 || page.Limit > maxNearbyLimit {
+		page.Limit = defaultNearbyLimit
+	}
+	if query.Cursor != "" {
+		page.After, err = decodeNearbyCursor(query.Cursor, page.Sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	jobs, hasMore, err := uc.jobRepo.FindNearby(query.Latitude, query.Longitude, radius, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NearbyJobsPage{Jobs: jobs}
+	if hasMore {
+		result.NextCursor = encodeNearbyCursor(page.Sort, repository.CursorFor(jobs[len(jobs)-1], page.Sort))
+	}
+	return result, nil
+}
+
+func (uc *JobUseCase) nearbyFilter(query NearbyQuery, now time.Time) (repository.NearbyFilter, error) {
+	filter := repository.NearbyFilter{
+		MinHourlyRate:     query.MinHourlyRate,
+		MinEmployerRating: query.MinEmployerRating,
+		Keyword:           strings.TrimSpace(query.Keyword),
+	}
+	if query.StartsWithinHours > 0 {
+		filter.StartsAfter = now
+		filter.StartsBefore = now.Add(time.Duration(query.StartsWithinHours * float64(time.Hour)))
+	}
+
+	if query.TodayOnly {
+		loc, err := time.LoadLocation(uc.cfg.App.DefaultTimezone)
+		if err != nil {
+			return filter, errors.New("invalid default timezone")
+		}
+		local := now.In(loc)
+		endOfDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+
+		filter.StartsAfter = now
+		if filter.StartsBefore.IsZero() || endOfDay.Before(filter.StartsBefore) {
+			filter.StartsBefore = endOfDay
+		}
+	}
+
+	return filter, nil
 }
 
 func (uc *JobUseCase) GetByID(id uuid.UUID) (*domain.Job, error) {
@@ -109,6 +341,7 @@ func (uc *JobUseCase) populateRatingStatus(job *domain.Job) {
 	if job.Status != domain.JobStatusDone {
 		return
 	}
+	job.RatingDeadline = ratingDeadline(job, uc.cfg.Rating)
 	// Check employer rating
 	rated, _ := uc.ratingRepo.Exists(job.ID, job.EmployerID)
 	job.EmployerRated = rated
@@ -121,24 +354,57 @@ func (uc *JobUseCase) populateRatingStatus(job *domain.Job) {
 }
 
 func (uc *JobUseCase) Assign(jobID, workerID uuid.UUID, employerID uuid.UUID) (*domain.Job, error) {
-	job, err := uc.jobRepo.FindByID(jobID)
-	if err != nil {
-		return nil, errors.New("job not found")
-	}
+	var job *domain.Job
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		var err error
+		job, err = repos.Jobs.FindByIDForUpdate(jobID)
+		if err != nil {
+			return errors.New("job not found")
+		}
 
-	if job.EmployerID != employerID {
-		return nil, errors.New("only the employer can assign workers")
-	}
+		if job.EmployerID != employerID {
+			return errors.New("only the employer can assign workers")
+		}
+		if workerID == employerID {
+			return errors.New("you cannot assign yourself to your own job")
+		}
 
-	if job.Status != domain.JobStatusOpen {
-		return nil, errors.New("job is not open for assignment")
-	}
+		if job.Status == domain.JobStatusAssigned {
+			return ErrJobAlreadyAssigned
+		}
+		if job.Status != domain.JobStatusOpen {
+			return errors.New("job is not open for assignment")
+		}
 
-	job.Status = domain.JobStatusAssigned
-	job.AssignedWorkerID = &workerID
+		// A worker who applied can only be assigned while the application is
+		// pending, not after withdrawing or being declined
+		app, _ := repos.Applications.FindByWorkerAndJob(workerID, jobID)
+		if app != nil && app.Status != domain.ApplicationStatusPending {
+			return errors.New("worker's application is no longer pending")
+		}
 
-	if err := uc.jobRepo.Update(job); err != nil {
-		return nil, errors.New("failed to assign worker")
+		job.Status = domain.JobStatusAssigned
+		job.AssignedWorkerID = &workerID
+
+		if err := repos.Jobs.Update(job); err != nil {
+			return errors.New("failed to assign worker")
+		}
+
+		// Keep the worker's own application in step with the assignment
+		if app != nil {
+			app.Status = domain.ApplicationStatusAccepted
+			if err := repos.Applications.Update(app); err != nil {
+				return errors.New("failed to accept application")
+			}
+		}
+
+		if err := repos.Applications.RejectPendingByJobID(jobID, domain.RejectionReasonFilled); err != nil {
+			return errors.New("failed to reject remaining applications")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return job, nil
@@ -158,7 +424,9 @@ func (uc *JobUseCase) Complete(jobID, userID uuid.UUID) (*domain.Job, error) {
 		return nil, errors.New("job must be assigned before completing")
 	}
 
+	now := time.Now()
 	job.Status = domain.JobStatusDone
+	job.CompletedAt = &now
 
 	if err := uc.jobRepo.Update(job); err != nil {
 		return nil, errors.New("failed to complete job")
@@ -166,3 +434,144 @@ func (uc *JobUseCase) Complete(jobID, userID uuid.UUID) (*domain.Job, error) {
 
 	return job, nil
 }
+
+func (uc *JobUseCase) Cancel(jobID, employerID uuid.UUID, input CancelJobInput) (*domain.Job, error) {
+	var job *domain.Job
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		var err error
+		job, err = repos.Jobs.FindByIDForUpdate(jobID)
+		if err != nil {
+			return errors.New("job not found")
+		}
+
+		if job.EmployerID != employerID {
+			return errors.New("only the employer can cancel the job")
+		}
+
+		return cancelJob(repos, job, input.Reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// cancelJob cancels an open or assigned job and rejects its applications.
+// job must have been loaded for update in the transaction of repos.
+func cancelJob(repos *repository.Repositories, job *domain.Job, reason string) error {
+	if job.Status != domain.JobStatusOpen && job.Status != domain.JobStatusAssigned {
+		return errors.New("only open or assigned jobs can be cancelled")
+	}
+
+	now := time.Now()
+	job.Status = domain.JobStatusCancelled
+	job.CancelReason = reason
+	job.CancelledAt = &now
+
+	if err := repos.Jobs.Update(job); err != nil {
+		return errors.New("failed to cancel job")
+	}
+
+	if job.AssignedWorkerID != nil {
+		if app, _ := repos.Applications.FindByWorkerAndJob(*job.AssignedWorkerID, job.ID); app != nil {
+			app.Status = domain.ApplicationStatusRejected
+			app.RejectionReason = domain.RejectionReasonCancelled
+			if err := repos.Applications.Update(app); err != nil {
+				return errors.New("failed to update assigned application")
+			}
+		}
+	}
+
+	if err := repos.Applications.RejectPendingByJobID(job.ID, domain.RejectionReasonCancelled); err != nil {
+		return errors.New("failed to reject pending applications")
+	}
+	return nil
+}
+
+// Unassign removes the assigned worker and reopens the job. It can be called
+// by the employer or by the assigned worker backing out; applications that
+// were rejected because the job was filled become pending again.
+func (uc *JobUseCase) Unassign(jobID, userID uuid.UUID, input UnassignInput) (*domain.Job, error) {
+	var job *domain.Job
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		var err error
+		job, err = repos.Jobs.FindByIDForUpdate(jobID)
+		if err != nil {
+			return errors.New("job not found")
+		}
+
+		if job.Status != domain.JobStatusAssigned || job.AssignedWorkerID == nil {
+			return errors.New("job has no assigned worker")
+		}
+
+		workerID := *job.AssignedWorkerID
+		if userID != job.EmployerID && userID != workerID {
+			return errors.New("only the employer or the assigned worker can unassign")
+		}
+
+		if err := repos.Jobs.CreateUnassignment(&domain.JobUnassignment{
+			JobID:    jobID,
+			WorkerID: workerID,
+			ByUserID: userID,
+			Reason:   input.Reason,
+		}); err != nil {
+			return errors.New("failed to record unassignment")
+		}
+
+		if app, _ := repos.Applications.FindByWorkerAndJob(workerID, jobID); app != nil {
+			if userID == workerID {
+				app.Status = domain.ApplicationStatusWithdrawn
+			} else {
+				app.Status = domain.ApplicationStatusRejected
+				app.RejectionReason = domain.RejectionReasonUnassigned
+			}
+			if err := repos.Applications.Update(app); err != nil {
+				return errors.New("failed to update assigned application")
+			}
+		}
+
+		job.Status = domain.JobStatusOpen
+		job.AssignedWorkerID = nil
+		if err := repos.Jobs.Update(job); err != nil {
+			return errors.New("failed to reopen job")
+		}
+
+		if err := repos.Applications.ReopenRejectedByJobID(jobID, domain.RejectionReasonFilled); err != nil {
+			return errors.New("failed to reopen applications")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ExpireStale expires open jobs whose start time has passed by more than
+// the configured grace or that have been open longer than the configured
+// TTL, rejecting their pending applications. It returns the number of jobs
+// expired.
+func (uc *JobUseCase) ExpireStale() (int, error) {
+	// Never expire a job that Create would still accept as starting now
+	grace := uc.cfg.App.OpenJobStartGrace
+	if grace < startTimeGrace {
+		grace = startTimeGrace
+	}
+
+	now := time.Now()
+	var expired int
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		ids, err := repos.Jobs.ExpireOpen(now.Add(-grace), now.Add(-uc.cfg.App.OpenJobTTL))
+		if err != nil {
+			return err
+		}
+		expired = len(ids)
+		if expired == 0 {
+			return nil
+		}
+		return repos.Applications.RejectPendingByJobIDs(ids, domain.RejectionReasonExpired)
+	})
+	return expired, err
+}
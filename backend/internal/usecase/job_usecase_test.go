@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/repository"
+)
+
+func TestNearbyCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("6f1c2a9e-4b1d-4c47-9a55-2f0e7d1b3c84")
+	at := time.Date(2024, 3, 9, 14, 30, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		sort   repository.NearbySort
+		cursor repository.NearbyCursor
+	}{
+		{repository.NearbySortDistance, repository.NearbyCursor{Value: 1234.5678, ID: id}},
+		{repository.NearbySortHourlyRate, repository.NearbyCursor{Value: 50000, ID: id}},
+		{repository.NearbySortNewest, repository.NearbyCursor{Time: at, ID: id}},
+		{repository.NearbySortStartTime, repository.NearbyCursor{Time: at, ID: id}},
+		{repository.NearbySortDistance, repository.NearbyCursor{ID: id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			encoded := encodeNearbyCursor(tt.sort, tt.cursor)
+			decoded, err := decodeNearbyCursor(encoded, tt.sort)
+			if err != nil {
+				t.Fatalf("decodeNearbyCursor(%q): %v", encoded, err)
+			}
+			if decoded.Value != tt.cursor.Value || !decoded.Time.Equal(tt.cursor.Time) || decoded.ID != tt.cursor.ID {
+				t.Errorf("decoded %+v, want %+v", *decoded, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeNearbyCursorRejects(t *testing.T) {
+	valid := encodeNearbyCursor(repository.NearbySortDistance, repository.NearbyCursor{Value: 10, ID: uuid.New()})
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		sort  repository.NearbySort
+	}{
+		{"other sort", valid, repository.NearbySortHourlyRate},
+		{"not base64", "not base64!", repository.NearbySortDistance},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"distance"}`)), repository.NearbySortDistance},
+		{"not JSON", encode("distance"), repository.NearbySortDistance},
+		{"missing sort", encode(`{"at":{"v":10}}`), repository.NearbySortDistance},
+		{"malformed position", encode(`{"s":"distance","at":{"v":"far"}}`), repository.NearbySortDistance},
+		{"malformed ID", encode(`{"s":"distance","at":{"id":"nope"}}`), repository.NearbySortDistance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeNearbyCursor(tt.value, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeNearbyCursor(%q) error = %v, want ErrInvalidCursor", tt.value, err)
+			}
+		})
+	}
+}
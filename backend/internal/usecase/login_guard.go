@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/work-near-me/backend/config"
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg/sms"
+)
+
+// ClientInfo describes where a request came from, for audit records.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginLockedError is returned for logins to a phone number that is locked
+// out after too many failed attempts.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, please try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+const lockoutMessage = "Someone failed to sign in to your ShortJob account %d times. Sign-in is paused for %s. If this wasn't you, reset your password."
+
+// LoginGuard tracks failed logins per phone number, independently of the
+// client's IP address, and locks a number out for exponentially longer
+// periods while failures continue. The owner is notified by SMS when a
+// lockout starts.
+type LoginGuard struct {
+	attempts *repository.LoginAttemptRepository
+	sender   sms.Sender
+	cfg      config.LoginConfig
+}
+
+func NewLoginGuard(attempts *repository.LoginAttemptRepository, sender sms.Sender, cfg config.LoginConfig) *LoginGuard {
+	return &LoginGuard{attempts: attempts, sender: sender, cfg: cfg}
+}
+
+// Allow returns a *LoginLockedError while phone is locked out. When Redis is
+// unavailable logins are allowed, as with the per-IP rate limit.
+func (g *LoginGuard) Allow(phone string) error {
+	wait, err := g.attempts.LockedFor(phone)
+	if err != nil {
+		log.Printf("LoginGuard: lockout check failed: %v", err)
+		return nil
+	}
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Failed records a failed login for phone and returns the lockout it
+// started, or zero. user is nil when the number is not registered.
+func (g *LoginGuard) Failed(phone string, user *domain.User) time.Duration {
+	failures, err := g.attempts.RecordFailure(phone, g.cfg.FailureWindow)
+	if err != nil {
+		log.Printf("LoginGuard: recording failure failed: %v", err)
+		return 0
+	}
+
+	excess := failures - int64(g.cfg.FreeAttempts)
+	if excess <= 0 {
+		return 0
+	}
+
+	lockout := g.cfg.LockoutMax
+	if excess <= 30 {
+		if d := g.cfg.LockoutBase << (excess - 1); d > 0 && d < lockout {
+			lockout = d
+		}
+	}
+	if err := g.attempts.Lock(phone, lockout); err != nil {
+		log.Printf("LoginGuard: lock failed: %v", err)
+		return 0
+	}
+
+	// Tell the owner once per run of failures, not on every extension
+	if excess == 1 && user != nil {
+		message := fmt.Sprintf(lockoutMessage, failures, lockout.Round(time.Second))
+		if err := g.sender.Send(user.Phone, message); err != nil {
+			log.Printf("LoginGuard: lockout notification to %s failed: %v", user.Phone, err)
+		}
+	}
+	return lockout
+}
+
+// Succeeded clears the failures and any lockout of phone.
+func (g *LoginGuard) Succeeded(phone string) {
+	if err := g.attempts.Reset(phone); err != nil {
+		log.Printf("LoginGuard: reset failed: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/work-near-me/backend/config"
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg"
+	"github.com/work-near-me/backend/pkg/sms"
+)
+
+var (
+	ErrInvalidOTP = errors.New("invalid or expired code")
+	// ErrOTPAttemptsExceeded means the code was discarded after too many
+	// wrong guesses and a new one has to be requested.
+	ErrOTPAttemptsExceeded = errors.New("too many incorrect attempts, please request a new code")
+)
+
+// CooldownError is returned when a code is requested again too soon.
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("please wait %d seconds before requesting another code", int(e.RetryAfter.Round(time.Second).Seconds()))
+}
+
+// OTPService sends one-time codes by SMS and checks them. Codes are stored
+// hashed and bound to a purpose and phone number.
+type OTPService struct {
+	otpRepo *repository.OTPRepository
+	sender  sms.Sender
+	cfg     config.OTPConfig
+}
+
+func NewOTPService(otpRepo *repository.OTPRepository, sender sms.Sender, cfg config.OTPConfig) *OTPService {
+	return &OTPService{otpRepo: otpRepo, sender: sender, cfg: cfg}
+}
+
+// OTPSent tells the client how long the code is valid and when another one
+// may be requested.
+type OTPSent struct {
+	ExpiresIn   int `json:"expires_in"`
+	ResendAfter int `json:"resend_after"`
+}
+
+// Send texts a new code for purpose to phone, replacing any pending one.
+// message is a format string receiving the code and its lifetime in minutes.
+func (s *OTPService) Send(purpose domain.OTPPurpose, phone, message string) (*OTPSent, error) {
+	sent, err := s.Throttle(purpose, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := pkg.GenerateOTP(s.cfg.Length)
+	if err != nil {
+		return nil, errors.New("failed to send code")
+	}
+	if err := s.otpRepo.Store(purpose, phone, hashOTP(purpose, phone, code), s.cfg.TTL); err != nil {
+		log.Printf("OTP: store failed: %v", err)
+		return nil, errors.New("failed to send code")
+	}
+
+	minutes := int(s.cfg.TTL.Round(time.Minute).Minutes())
+	if err := s.sender.Send(phone, fmt.Sprintf(message, code, minutes)); err != nil {
+		log.Printf("OTP: SMS to %s failed: %v", phone, err)
+		return nil, errors.New("failed to send code")
+	}
+
+	return sent, nil
+}
+
+// Throttle applies the send limits of Send without sending anything. Flows
+// that must not reveal whether a phone number is registered use it in place
+// of Send for unknown numbers, so both cases answer alike.
+func (s *OTPService) Throttle(purpose domain.OTPPurpose, phone string) (*OTPSent, error) {
+	wait, err := s.otpRepo.ReserveSend(purpose, phone, s.cfg.ResendCooldown, s.cfg.MaxSendsPerHour)
+	if err != nil {
+		log.Printf("OTP: reserve send failed: %v", err)
+		return nil, errors.New("failed to send code")
+	}
+	if wait > 0 {
+		return nil, &CooldownError{RetryAfter: wait}
+	}
+
+	return &OTPSent{
+		ExpiresIn:   int(s.cfg.TTL.Seconds()),
+		ResendAfter: int(s.cfg.ResendCooldown.Seconds()),
+	}, nil
+}
+
+// Check consumes the pending code for purpose and phone if code matches it.
+func (s *OTPService) Check(purpose domain.OTPPurpose, phone, code string) error {
+	result, err := s.otpRepo.Check(purpose, phone, hashOTP(purpose, phone, code), s.cfg.MaxAttempts)
+	if err != nil {
+		log.Printf("OTP: check failed: %v", err)
+		return errors.New("failed to check code")
+	}
+
+	switch result {
+	case repository.OTPMatched:
+		return nil
+	case repository.OTPLocked:
+		return ErrOTPAttemptsExceeded
+	default:
+		return ErrInvalidOTP
+	}
+}
+
+// hashOTP binds the code to its purpose and phone number, so equal codes do
+// not produce equal hashes.
+func hashOTP(purpose domain.OTPPurpose, phone, code string) string {
+	return pkg.HashToken(string(purpose) + ":" + phone + ":" + code)
+}
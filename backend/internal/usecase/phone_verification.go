@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/repository"
+)
+
+var (
+	// ErrPhoneNotVerified is returned for actions that require a verified
+	// phone number while REQUIRE_PHONE_VERIFICATION is on.
+	ErrPhoneNotVerified     = errors.New("please verify your phone number first")
+	ErrPhoneAlreadyVerified = errors.New("phone number is already verified")
+)
+
+// PhoneVerificationPolicy decides whether a user may post jobs or apply
+// based on whether their phone number is verified.
+type PhoneVerificationPolicy struct {
+	userRepo *repository.UserRepository
+	required bool
+}
+
+func NewPhoneVerificationPolicy(userRepo *repository.UserRepository, required bool) *PhoneVerificationPolicy {
+	return &PhoneVerificationPolicy{userRepo: userRepo, required: required}
+}
+
+// Check returns ErrPhoneNotVerified when verification is required and the
+// user has not verified their phone number.
+func (p *PhoneVerificationPolicy) Check(userID uuid.UUID) error {
+	if !p.required {
+		return nil
+	}
+
+	user, err := p.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.PhoneVerified {
+		return ErrPhoneNotVerified
+	}
+	return nil
+}
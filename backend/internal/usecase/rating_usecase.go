@@ -2,30 +2,90 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/work-near-me/backend/config"
 	"github.com/work-near-me/backend/internal/domain"
 	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg/notify"
 )
 
 type RatingUseCase struct {
-	ratingRepo *repository.RatingRepository
-	userRepo   *repository.UserRepository
-	jobRepo    *repository.JobRepository
+	ratingRepo   *repository.RatingRepository
+	userRepo     *repository.UserRepository
+	jobRepo      *repository.JobRepository
+	reminderRepo *repository.RatingReminderRepository
+	uow          *repository.UnitOfWork
+	notifier     notify.Notifier
+	cfg          config.RatingConfig
 }
 
 func NewRatingUseCase(
 	ratingRepo *repository.RatingRepository,
 	userRepo *repository.UserRepository,
 	jobRepo *repository.JobRepository,
+	reminderRepo *repository.RatingReminderRepository,
+	uow *repository.UnitOfWork,
+	notifier notify.Notifier,
+	cfg config.RatingConfig,
 ) *RatingUseCase {
 	return &RatingUseCase{
-		ratingRepo: ratingRepo,
-		userRepo:   userRepo,
-		jobRepo:    jobRepo,
+		ratingRepo:   ratingRepo,
+		userRepo:     userRepo,
+		jobRepo:      jobRepo,
+		reminderRepo: reminderRepo,
+		uow:          uow,
+		notifier:     notifier,
+		cfg:          cfg,
 	}
 }
 
+const ratingReminderMessage = "How did \"%s\" go? Rate the %s on ShortJob before %s."
+
+// reminderBatchSize caps the reminders sent per SendReminders run.
+const reminderBatchSize = 100
+
+// ErrNotJobParticipant is returned when someone other than the employer or
+// the assigned worker asks for the ratings of a job.
+var ErrNotJobParticipant = errors.New("only participants of the job can see its ratings")
+
+// Review is a rating as shown to other users: the rater's name and the job
+// title, without the contact details of either participant. RevealedAt is
+// nil on the viewer's own rating while it is still hidden from the other
+// participant.
+type Review struct {
+	ID         uuid.UUID       `json:"id"`
+	JobID      uuid.UUID       `json:"job_id"`
+	JobTitle   string          `json:"job_title"`
+	FromUserID uuid.UUID       `json:"from_user_id"`
+	FromName   string          `json:"from_name"`
+	ToUserID   uuid.UUID       `json:"to_user_id"`
+	ToRole     domain.UserRole `json:"to_role"`
+	Score      int             `json:"score"`
+	Comment    string          `json:"comment"`
+	RevealedAt *time.Time      `json:"revealed_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type UserRatingsQuery struct {
+	Role  domain.UserRole `form:"role" binding:"omitempty,oneof=employer worker"`
+	Page  int             `form:"page" binding:"omitempty,min=1"`
+	Limit int             `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ReviewPage is one page of the ratings a user received. Histogram counts
+// all of them by score, from 1 to 5.
+type ReviewPage struct {
+	Ratings   []Review      `json:"ratings"`
+	Histogram map[int]int64 `json:"histogram"`
+	Page      int           `json:"page"`
+	Limit     int           `json:"limit"`
+	Total     int64         `json:"total"`
+}
+
 type CreateRatingInput struct {
 	JobID    uuid.UUID `json:"job_id" binding:"required"`
 	ToUserID uuid.UUID `json:"to_user_id" binding:"required"`
@@ -33,53 +93,269 @@ type CreateRatingInput struct {
 	Comment  string    `json:"comment"`
 }
 
+// Create records the rating of one participant of a done job for the
+// other, up to the rating deadline. The rating stays hidden until the other participant has rated too,
+// or RevealDue reveals it once the reveal window has passed.
 func (uc *RatingUseCase) Create(fromUserID uuid.UUID, input CreateRatingInput) (*domain.Rating, error) {
-	// Verify job is done
-	job, err := uc.jobRepo.FindByID(input.JobID)
+	var rating *domain.Rating
+	// The job row lock serializes the two participants, so exactly one of
+	// them sees both ratings and reveals them
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		job, err := repos.Jobs.FindByIDForUpdate(input.JobID)
+		if err != nil {
+			return errors.New("job not found")
+		}
+
+		if job.Status != domain.JobStatusDone {
+			return errors.New("can only rate after job is completed")
+		}
+		if deadline := ratingDeadline(job, uc.cfg); deadline != nil && time.Now().After(*deadline) {
+			return errors.New("the rating period for this job has ended")
+		}
+
+		// Verify the rater is either the employer or assigned worker
+		if job.EmployerID != fromUserID && (job.AssignedWorkerID == nil || *job.AssignedWorkerID != fromUserID) {
+			return errors.New("only participants can rate")
+		}
+
+		// Verify the target is the other participant
+		if input.ToUserID != job.EmployerID && (job.AssignedWorkerID == nil || input.ToUserID != *job.AssignedWorkerID) {
+			return errors.New("can only rate the other participant")
+		}
+
+		// Check if already rated
+		exists, err := repos.Ratings.Exists(input.JobID, fromUserID)
+		if err != nil {
+			return errors.New("failed to check existing rating")
+		}
+		if exists {
+			return errors.New("you have already rated for this job")
+		}
+
+		toRole := domain.RoleWorker
+		if input.ToUserID == job.EmployerID {
+			toRole = domain.RoleEmployer
+		}
+
+		rating = &domain.Rating{
+			JobID:      input.JobID,
+			FromUserID: fromUserID,
+			ToUserID:   input.ToUserID,
+			ToRole:     toRole,
+			Score:      input.Score,
+			Comment:    input.Comment,
+		}
+		if err := repos.Ratings.Create(rating); err != nil {
+			return errors.New("failed to create rating")
+		}
+
+		hidden, err := repos.Ratings.FindUnrevealedByJobID(job.ID)
+		if err != nil {
+			return errors.New("failed to create rating")
+		}
+		if len(hidden) < 2 {
+			return nil
+		}
+
+		now := time.Now()
+		if err := revealRatings(repos, hidden, now); err != nil {
+			return err
+		}
+		rating.RevealedAt = &now
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("job not found")
+		return nil, err
 	}
 
-	if job.Status != domain.JobStatusDone {
-		return nil, errors.New("can only rate after job is completed")
+	return rating, nil
+}
+
+// RevealDue reveals the hidden ratings of jobs completed longer than the
+// reveal window ago, whether or not the other participant has rated.
+func (uc *RatingUseCase) RevealDue() (int, error) {
+	due, err := uc.ratingRepo.FindDueForReveal(time.Now().Add(-uc.cfg.RevealWindow))
+	if err != nil || len(due) == 0 {
+		return 0, err
 	}
 
-	// Verify the rater is either the employer or assigned worker
-	if job.EmployerID != fromUserID && (job.AssignedWorkerID == nil || *job.AssignedWorkerID != fromUserID) {
-		return nil, errors.New("only participants can rate")
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		return revealRatings(repos, due, time.Now())
+	})
+	if err != nil {
+		return 0, err
 	}
+	return len(due), nil
+}
 
-	// Verify the target is the other participant
-	if input.ToUserID != job.EmployerID && (job.AssignedWorkerID == nil || input.ToUserID != *job.AssignedWorkerID) {
-		return nil, errors.New("can only rate the other participant")
+// SendReminders reminds participants of completed jobs to rate the other
+// side once the reminder delay has passed, while they still can. Each
+// participant is reminded once per job; a failed delivery is retried with a
+// backoff until the attempts run out. A reminder that cannot be prepared is
+// logged and skipped so it does not hold up the rest.
+func (uc *RatingUseCase) SendReminders() (int, error) {
+	now := time.Now()
+	due, err := uc.reminderRepo.FindDue(now.Add(-uc.cfg.Window), now.Add(-uc.cfg.ReminderAfter),
+		now, uc.cfg.ReminderRetryDelay, uc.cfg.ReminderMaxAttempts, reminderBatchSize)
+	if err != nil {
+		return 0, err
 	}
 
-	// Check if already rated
-	exists, err := uc.ratingRepo.Exists(input.JobID, fromUserID)
+	sent := 0
+	for _, reminder := range due {
+		job, err := uc.jobRepo.FindByID(reminder.JobID)
+		if err != nil {
+			log.Printf("Ratings: loading job %s for a reminder failed: %v", reminder.JobID, err)
+			continue
+		}
+		user, err := uc.userRepo.FindByID(reminder.UserID)
+		if err != nil {
+			log.Printf("Ratings: loading user %s for a reminder failed: %v", reminder.UserID, err)
+			continue
+		}
+
+		other := "worker"
+		if reminder.UserID != job.EmployerID {
+			other = "employer"
+		}
+		deadline := *ratingDeadline(job, uc.cfg)
+		if loc, err := time.LoadLocation(job.Timezone); job.Timezone != "" && err == nil {
+			deadline = deadline.In(loc)
+		}
+		message := fmt.Sprintf(ratingReminderMessage, job.Title, other, deadline.Format("Jan 2 15:04 MST"))
+
+		attemptedAt := time.Now()
+		reminder.LastAttemptAt = &attemptedAt
+		if err := uc.notifier.Notify(notify.Notification{UserID: user.ID, Phone: user.Phone, Message: message}); err != nil {
+			log.Printf("Ratings: reminding user %s about job %s failed: %v", user.ID, job.ID, err)
+		} else {
+			reminder.SentAt = &attemptedAt
+			sent++
+		}
+
+		if err := uc.reminderRepo.RecordAttempt(&reminder); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// ratingDeadline returns when rating a done job closes, or nil for jobs
+// completed before completion times were recorded.
+func ratingDeadline(job *domain.Job, cfg config.RatingConfig) *time.Time {
+	if job.CompletedAt == nil {
+		return nil
+	}
+	deadline := job.CompletedAt.Add(cfg.Window)
+	return &deadline
+}
+
+// revealRatings reveals ratings at the given time and recomputes the
+// aggregates they now count towards.
+func revealRatings(repos *repository.Repositories, ratings []domain.Rating, at time.Time) error {
+	type target struct {
+		userID uuid.UUID
+		role   domain.UserRole
+	}
+
+	ids := make([]uuid.UUID, len(ratings))
+	targets := make(map[target]bool)
+	for i, rating := range ratings {
+		ids[i] = rating.ID
+		targets[target{rating.ToUserID, rating.ToRole}] = true
+	}
+
+	if err := repos.Ratings.Reveal(ids, at); err != nil {
+		return errors.New("failed to reveal ratings")
+	}
+	for t := range targets {
+		avg, count, err := repos.Ratings.GetUserRatingStats(t.userID, t.role)
+		if err != nil {
+			return errors.New("failed to update rating")
+		}
+		if err := repos.Users.UpdateRating(t.userID, t.role, avg, count); err != nil {
+			return errors.New("failed to update rating")
+		}
+	}
+	return nil
+}
+
+// ListReceived returns the ratings userID received, in query.Role if set.
+func (uc *RatingUseCase) ListReceived(userID uuid.UUID, query UserRatingsQuery) (*ReviewPage, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil || user.Status == domain.UserStatusDeleted {
+		return nil, ErrUserNotFound
+	}
+
+	page, limit := pageBounds(query.Page, query.Limit)
+	ratings, total, err := uc.ratingRepo.FindReceived(userID, query.Role, (page-1)*limit, limit)
 	if err != nil {
-		return nil, errors.New("failed to check existing rating")
+		return nil, errors.New("failed to list ratings")
 	}
-	if exists {
-		return nil, errors.New("you have already rated for this job")
+	counts, err := uc.ratingRepo.ScoreHistogram(userID, query.Role)
+	if err != nil {
+		return nil, errors.New("failed to list ratings")
 	}
 
-	rating := &domain.Rating{
-		JobID:      input.JobID,
-		FromUserID: fromUserID,
-		ToUserID:   input.ToUserID,
-		Score:      input.Score,
-		Comment:    input.Comment,
+	histogram := make(map[int]int64, 5)
+	for score := 1; score <= 5; score++ {
+		histogram[score] = counts[score]
 	}
 
-	if err := uc.ratingRepo.Create(rating); err != nil {
-		return nil, errors.New("failed to create rating")
+	return &ReviewPage{
+		Ratings:   reviews(ratings),
+		Histogram: histogram,
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+	}, nil
+}
+
+// ListForJob returns the ratings given for a job to one of its participants:
+// their own, and the other participant's once it is revealed.
+func (uc *RatingUseCase) ListForJob(jobID, userID uuid.UUID) ([]Review, error) {
+	job, err := uc.jobRepo.FindByID(jobID)
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+	if job.EmployerID != userID && (job.AssignedWorkerID == nil || *job.AssignedWorkerID != userID) {
+		return nil, ErrNotJobParticipant
 	}
 
-	// Update target user's average rating
-	avg, count, err := uc.ratingRepo.GetUserRatingStats(input.ToUserID)
-	if err == nil {
-		_ = uc.userRepo.UpdateRating(input.ToUserID, avg, count)
+	ratings, err := uc.ratingRepo.FindByJobID(jobID)
+	if err != nil {
+		return nil, errors.New("failed to list ratings")
 	}
 
-	return rating, nil
+	visible := ratings[:0]
+	for _, rating := range ratings {
+		if rating.RevealedAt != nil || rating.FromUserID == userID {
+			visible = append(visible, rating)
+		}
+	}
+	return reviews(visible), nil
+}
+
+func reviews(ratings []domain.Rating) []Review {
+	views := make([]Review, len(ratings))
+	for i, rating := range ratings {
+		views[i] = Review{
+			ID:         rating.ID,
+			JobID:      rating.JobID,
+			FromUserID: rating.FromUserID,
+			ToUserID:   rating.ToUserID,
+			ToRole:     rating.ToRole,
+			Score:      rating.Score,
+			Comment:    rating.Comment,
+			RevealedAt: rating.RevealedAt,
+			CreatedAt:  rating.CreatedAt,
+		}
+		if rating.Job != nil {
+			views[i].JobTitle = rating.Job.Title
+		}
+		if rating.FromUser != nil {
+			views[i].FromName = rating.FromUser.Name
+		}
+	}
+	return views
 }
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/config"
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionUseCase lists and revokes the logins of a user. A session is
+// created at each login and lives as long as its refresh tokens; revoking
+// it revokes those tokens and denylists the session so its access tokens
+// are rejected before they expire.
+type SessionUseCase struct {
+	sessionRepo *repository.SessionRepository
+	denylist    *repository.DenylistRepository
+	uow         *repository.UnitOfWork
+	cfg         *config.Config
+}
+
+func NewSessionUseCase(
+	sessionRepo *repository.SessionRepository,
+	denylist *repository.DenylistRepository,
+	uow *repository.UnitOfWork,
+	cfg *config.Config,
+) *SessionUseCase {
+	return &SessionUseCase{
+		sessionRepo: sessionRepo,
+		denylist:    denylist,
+		uow:         uow,
+		cfg:         cfg,
+	}
+}
+
+// SessionView is a session as shown to its owner. Current marks the session
+// the request was made from.
+type SessionView struct {
+	domain.Session
+	Current bool `json:"current"`
+}
+
+func (uc *SessionUseCase) List(userID, currentID uuid.UUID) ([]SessionView, error) {
+	sessions, err := uc.sessionRepo.ListActive(userID)
+	if err != nil {
+		return nil, errors.New("failed to list sessions")
+	}
+
+	views := make([]SessionView, len(sessions))
+	for i, session := range sessions {
+		views[i] = SessionView{Session: session, Current: session.ID == currentID}
+	}
+	return views, nil
+}
+
+// Revoke signs one session of the user out. Revoking the current session
+// is allowed and works like logging out.
+func (uc *SessionUseCase) Revoke(userID, sessionID uuid.UUID) error {
+	if _, err := uc.sessionRepo.FindActive(userID, sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	err := uc.uow.Do(func(repos *repository.Repositories) error {
+		if err := repos.Sessions.Revoke(sessionID); err != nil {
+			return errors.New("failed to revoke session")
+		}
+		if err := repos.RefreshTokens.RevokeFamily(sessionID); err != nil {
+			return errors.New("failed to revoke session")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.deny(sessionID)
+	return nil
+}
+
+// PurgeExpired deletes sessions past their expiry.
+func (uc *SessionUseCase) PurgeExpired() (int64, error) {
+	return uc.sessionRepo.DeleteExpired(time.Now())
+}
+
+// deny adds revoked sessions to the denylist for as long as an access token
+// issued to them can live. Callers revoke the sessions in the database
+// first, so a failure here only delays the sign-out until those tokens
+// expire.
+func (uc *SessionUseCase) deny(ids ...uuid.UUID) {
+	if len(ids) == 0 {
+		return
+	}
+	if err := uc.denylist.AddSessions(ids, uc.cfg.JWT.AccessExpiry); err != nil {
+		log.Printf("Sessions: denylisting %d sessions failed: %v", len(ids), err)
+	}
+}
+
+// blockUser denylists the user so access tokens already issued to them are
+// rejected on their next request. Past the access token lifetime the status
+// check at refresh keeps them out.
+func (uc *SessionUseCase) blockUser(userID uuid.UUID) {
+	if err := uc.denylist.BlockUser(userID, uc.cfg.JWT.AccessExpiry); err != nil {
+		log.Printf("Sessions: denylisting user %s failed: %v", userID, err)
+	}
+}
+
+// unblockUser takes the user off the denylist, so a reinstated user can use
+// new tokens at once.
+func (uc *SessionUseCase) unblockUser(userID uuid.UUID) {
+	if err := uc.denylist.UnblockUser(userID); err != nil {
+		log.Printf("Sessions: removing user %s from the denylist failed: %v", userID, err)
+	}
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/config"
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+	"github.com/work-near-me/backend/pkg"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactor    = errors.New("invalid authentication code")
+	ErrIncorrectPassword   = errors.New("incorrect password")
+)
+
+// TwoFactorUseCase manages TOTP two-factor authentication: enrollment,
+// recovery codes, and checking a second factor at login. Wrong codes given
+// while signed in count towards the same per-phone lockout as failed
+// logins, so a stolen session cannot guess its way to new recovery codes.
+type TwoFactorUseCase struct {
+	userRepo     *repository.UserRepository
+	recoveryRepo *repository.RecoveryCodeRepository
+	eventRepo    *repository.AuthEventRepository
+	uow          *repository.UnitOfWork
+	cipher       *pkg.Cipher
+	guard        *LoginGuard
+	cfg          config.TOTPConfig
+}
+
+func NewTwoFactorUseCase(
+	userRepo *repository.UserRepository,
+	recoveryRepo *repository.RecoveryCodeRepository,
+	eventRepo *repository.AuthEventRepository,
+	uow *repository.UnitOfWork,
+	cipher *pkg.Cipher,
+	guard *LoginGuard,
+	cfg config.TOTPConfig,
+) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		eventRepo:    eventRepo,
+		uow:          uow,
+		cipher:       cipher,
+		guard:        guard,
+		cfg:          cfg,
+	}
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+	// QRCode is a PNG data URI of URI, for authenticator apps to scan.
+	QRCode string `json:"qr_code"`
+}
+
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorInput struct {
+	Password string `json:"password" binding:"required"`
+	// Code is a TOTP code or a recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+func (uc *TwoFactorUseCase) Status(userID uuid.UUID) (*TwoFactorStatus, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	status := &TwoFactorStatus{Enabled: user.TOTPEnabled}
+	if user.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = uc.recoveryRepo.CountUnused(userID)
+		if err != nil {
+			return nil, errors.New("failed to count recovery codes")
+		}
+	}
+	return status, nil
+}
+
+// Enroll starts enrollment with a new secret. Two-factor authentication is
+// not enforced until Confirm succeeds with a code from the secret, so an
+// abandoned enrollment changes nothing; enrolling again replaces the secret.
+func (uc *TwoFactorUseCase) Enroll(userID uuid.UUID) (*TwoFactorEnrollment, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+	sealed, err := uc.cipher.Seal(secret)
+	if err != nil {
+		return nil, errors.New("failed to store secret")
+	}
+
+	user.TOTPSecret = sealed
+	user.TOTPLastStep = 0
+	if err := uc.userRepo.UpdateTOTP(user); err != nil {
+		return nil, errors.New("failed to store secret")
+	}
+
+	uri := pkg.TOTPURI(uc.cfg.Issuer, user.Phone, secret)
+	png, err := pkg.TOTPQRCode(uri)
+	if err != nil {
+		return nil, errors.New("failed to render QR code")
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves their
+// authenticator works, and returns the first set of recovery codes. The
+// codes are only ever shown here and by RegenerateRecoveryCodes.
+func (uc *TwoFactorUseCase) Confirm(userID uuid.UUID, input TwoFactorCodeInput, client ClientInfo) (*RecoveryCodes, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("start enrollment first")
+	}
+
+	err = uc.guarded(user, client, func() error {
+		return uc.checkTOTP(user, input.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		user.TOTPEnabled = true
+		if err := repos.Users.UpdateTOTP(user); err != nil {
+			return errors.New("failed to enable two-factor authentication")
+		}
+
+		codes, err = replaceRecoveryCodes(repos.RecoveryCodes, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAuthEvent(uc.eventRepo, domain.AuthEventMFAEnabled, user.Phone, user, client)
+	return &RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off. It asks for the password and
+// a second factor, so neither a stolen session nor a stolen password alone
+// can remove it.
+func (uc *TwoFactorUseCase) Disable(userID uuid.UUID, input DisableTwoFactorInput, client ClientInfo) error {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	err = uc.guarded(user, client, func() error {
+		if !pkg.CheckPassword(input.Password, user.PasswordHash) {
+			return ErrIncorrectPassword
+		}
+		return uc.Verify(user, input.Code)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		user.TOTPEnabled = false
+		user.TOTPSecret = ""
+		user.TOTPLastStep = 0
+		if err := repos.Users.UpdateTOTP(user); err != nil {
+			return errors.New("failed to disable two-factor authentication")
+		}
+		return repos.RecoveryCodes.DeleteForUser(user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	recordAuthEvent(uc.eventRepo, domain.AuthEventMFADisabled, user.Phone, user, client)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes. It takes a TOTP code
+// rather than a recovery code, so it cannot be used to mint fresh codes
+// from a leaked list.
+func (uc *TwoFactorUseCase) RegenerateRecoveryCodes(userID uuid.UUID, input TwoFactorCodeInput, client ClientInfo) (*RecoveryCodes, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	err = uc.guarded(user, client, func() error {
+		return uc.checkTOTP(user, input.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = uc.uow.Do(func(repos *repository.Repositories) error {
+		codes, err = replaceRecoveryCodes(repos.RecoveryCodes, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Verify checks a second factor for user: a six-digit TOTP code, or else an
+// unused recovery code, which is used up.
+func (uc *TwoFactorUseCase) Verify(user *domain.User, code string) error {
+	if isDigits(strings.TrimSpace(code)) {
+		return uc.checkTOTP(user, code)
+	}
+
+	used, err := uc.recoveryRepo.Consume(user.ID, hashRecoveryCode(user.ID, pkg.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return errors.New("failed to check recovery code")
+	}
+	if !used {
+		return ErrInvalidTwoFactor
+	}
+	return nil
+}
+
+// guarded runs check, a check of the user's password or second factor, under
+// the per-phone lockout: it is refused while the number is locked, and a
+// wrong password or code counts as a failed login.
+func (uc *TwoFactorUseCase) guarded(user *domain.User, client ClientInfo, check func() error) error {
+	if err := uc.guard.Allow(user.Phone); err != nil {
+		recordAuthEvent(uc.eventRepo, domain.AuthEventLoginBlocked, user.Phone, user, client)
+		return err
+	}
+
+	if err := check(); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactor) || errors.Is(err, ErrIncorrectPassword) {
+			recordAuthEvent(uc.eventRepo, domain.AuthEventMFAFailed, user.Phone, user, client)
+			if lockout := uc.guard.Failed(user.Phone, user); lockout > 0 {
+				recordAuthEvent(uc.eventRepo, domain.AuthEventAccountLocked, user.Phone, user, client)
+			}
+		}
+		return err
+	}
+
+	uc.guard.Succeeded(user.Phone)
+	return nil
+}
+
+// checkTOTP accepts a TOTP code once; the time step it matched is recorded
+// so the same code cannot be replayed.
+func (uc *TwoFactorUseCase) checkTOTP(user *domain.User, code string) error {
+	secret, err := uc.cipher.Open(user.TOTPSecret)
+	if err != nil {
+		return errors.New("failed to read two-factor secret")
+	}
+
+	step, ok := pkg.ValidateTOTP(secret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return ErrInvalidTwoFactor
+	}
+
+	advanced, err := uc.userRepo.AdvanceTOTPStep(user.ID, step)
+	if err != nil {
+		return errors.New("failed to check authentication code")
+	}
+	if !advanced {
+		return ErrInvalidTwoFactor
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+func replaceRecoveryCodes(repo *repository.RecoveryCodeRepository, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := pkg.GenerateRecoveryCode()
+		if err != nil {
+			return nil, errors.New("failed to generate recovery codes")
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(userID, code)
+	}
+
+	if err := repo.Replace(userID, hashes); err != nil {
+		return nil, errors.New("failed to store recovery codes")
+	}
+	return codes, nil
+}
+
+func hashRecoveryCode(userID uuid.UUID, code string) string {
+	return pkg.HashToken(userID.String() + ":" + code)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
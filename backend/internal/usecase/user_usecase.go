@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/internal/domain"
+	"github.com/work-near-me/backend/internal/repository"
+)
+
+// UserProfile is the public view of a user. Phone and the home location are
+// only filled in on the user's own profile.
+type UserProfile struct {
+	ID                    uuid.UUID        `json:"id"`
+	Name                  string           `json:"name"`
+	Bio                   string           `json:"bio"`
+	Role                  domain.UserRole  `json:"role"`
+	Roles                 domain.UserRoles `json:"roles"`
+	EmployerRatingAvg     float64          `json:"employer_rating_avg"`
+	EmployerRatingCount   int              `json:"employer_rating_count"`
+	WorkerRatingAvg       float64          `json:"worker_rating_avg"`
+	WorkerRatingCount     int              `json:"worker_rating_count"`
+	EmployerJobsCompleted int64            `json:"employer_jobs_completed"`
+	WorkerJobsCompleted   int64            `json:"worker_jobs_completed"`
+	MemberSince           time.Time        `json:"member_since"`
+	Phone                 string           `json:"phone,omitempty"`
+	Latitude              *float64         `json:"latitude,omitempty"`
+	Longitude             *float64         `json:"longitude,omitempty"`
+}
+
+// UpdateProfileInput changes only the fields that are present. The home
+// location is set as a whole.
+type UpdateProfileInput struct {
+	Name      *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	Bio       *string  `json:"bio" binding:"omitempty,max=1000"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+}
+
+type UserUseCase struct {
+	userRepo *repository.UserRepository
+	jobRepo  *repository.JobRepository
+}
+
+func NewUserUseCase(userRepo *repository.UserRepository, jobRepo *repository.JobRepository) *UserUseCase {
+	return &UserUseCase{userRepo: userRepo, jobRepo: jobRepo}
+}
+
+// GetProfile returns the profile of userID as seen by viewerID. Deleted
+// accounts are not found.
+func (uc *UserUseCase) GetProfile(userID, viewerID uuid.UUID) (*UserProfile, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil || user.Status == domain.UserStatusDeleted {
+		return nil, ErrUserNotFound
+	}
+	return uc.profile(user, userID == viewerID)
+}
+
+func (uc *UserUseCase) UpdateProfile(userID uuid.UUID, input UpdateProfileInput) (*UserProfile, error) {
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return nil, errors.New("latitude and longitude must be set together")
+	}
+
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		user.Name = name
+	}
+	if input.Bio != nil {
+		user.Bio = strings.TrimSpace(*input.Bio)
+	}
+	if input.Latitude != nil {
+		user.Latitude = *input.Latitude
+		user.Longitude = *input.Longitude
+	}
+
+	if err := uc.userRepo.UpdateProfile(user); err != nil {
+		return nil, errors.New("failed to update profile")
+	}
+	return uc.profile(user, true)
+}
+
+func (uc *UserUseCase) profile(user *domain.User, own bool) (*UserProfile, error) {
+	asEmployer, asWorker, err := uc.jobRepo.CountCompleted(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to load profile")
+	}
+
+	profile := &UserProfile{
+		ID:                    user.ID,
+		Name:                  user.Name,
+		Bio:                   user.Bio,
+		Role:                  user.Role,
+		Roles:                 user.Roles,
+		EmployerRatingAvg:     user.EmployerRatingAvg,
+		EmployerRatingCount:   user.EmployerRatingCount,
+		WorkerRatingAvg:       user.WorkerRatingAvg,
+		WorkerRatingCount:     user.WorkerRatingCount,
+		EmployerJobsCompleted: asEmployer,
+		WorkerJobsCompleted:   asWorker,
+		MemberSince:           user.CreatedAt,
+	}
+	if own {
+		profile.Phone = user.Phone
+		profile.Latitude = &user.Latitude
+		profile.Longitude = &user.Longitude
+	}
+	return profile, nil
+}
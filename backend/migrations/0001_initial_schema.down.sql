@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS job_unassignments;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS users;
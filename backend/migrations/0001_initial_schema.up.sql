@@ -0,0 +1,83 @@
+-- Baseline schema. Statements use IF NOT EXISTS so databases previously
+-- created by GORM AutoMigrate can adopt versioned migrations unchanged.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            uuid PRIMARY KEY,
+    name          varchar(255) NOT NULL,
+    phone         varchar(20) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    role          varchar(20) NOT NULL,
+    latitude      double precision,
+    longitude     double precision,
+    rating_avg    double precision DEFAULT 0,
+    rating_count  bigint DEFAULT 0,
+    created_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id                 uuid PRIMARY KEY,
+    employer_id        uuid NOT NULL REFERENCES users (id),
+    title              varchar(255) NOT NULL,
+    description        text,
+    hourly_rate        double precision,
+    total_payment      double precision,
+    start_time         timestamptz,
+    end_time           timestamptz,
+    timezone           varchar(64),
+    estimated_hours    double precision,
+    latitude           double precision NOT NULL,
+    longitude          double precision NOT NULL,
+    status             varchar(20) NOT NULL DEFAULT 'open',
+    assigned_worker_id uuid REFERENCES users (id),
+    cancel_reason      text,
+    cancelled_at       timestamptz,
+    created_at         timestamptz
+);
+-- Columns added to jobs after the first AutoMigrate schema.
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS start_time      timestamptz,
+    ADD COLUMN IF NOT EXISTS end_time        timestamptz,
+    ADD COLUMN IF NOT EXISTS timezone        varchar(64),
+    ADD COLUMN IF NOT EXISTS estimated_hours double precision,
+    ADD COLUMN IF NOT EXISTS cancel_reason   text,
+    ADD COLUMN IF NOT EXISTS cancelled_at    timestamptz;
+CREATE INDEX IF NOT EXISTS idx_jobs_employer_id ON jobs (employer_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_start_time ON jobs (start_time);
+CREATE INDEX IF NOT EXISTS idx_jobs_location ON jobs (latitude, longitude);
+
+CREATE TABLE IF NOT EXISTS job_unassignments (
+    id         uuid PRIMARY KEY,
+    job_id     uuid NOT NULL REFERENCES jobs (id),
+    worker_id  uuid NOT NULL REFERENCES users (id),
+    by_user_id uuid NOT NULL REFERENCES users (id),
+    reason     text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_job_unassignments_job_id ON job_unassignments (job_id);
+CREATE INDEX IF NOT EXISTS idx_job_unassignments_worker_id ON job_unassignments (worker_id);
+
+CREATE TABLE IF NOT EXISTS applications (
+    id               uuid PRIMARY KEY,
+    job_id           uuid NOT NULL REFERENCES jobs (id),
+    worker_id        uuid NOT NULL REFERENCES users (id),
+    status           varchar(20) NOT NULL DEFAULT 'pending',
+    rejection_reason varchar(30),
+    created_at       timestamptz
+);
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS rejection_reason varchar(30);
+CREATE INDEX IF NOT EXISTS idx_applications_job_id ON applications (job_id);
+CREATE INDEX IF NOT EXISTS idx_applications_worker_id ON applications (worker_id);
+
+CREATE TABLE IF NOT EXISTS ratings (
+    id           uuid PRIMARY KEY,
+    job_id       uuid NOT NULL REFERENCES jobs (id),
+    from_user_id uuid NOT NULL REFERENCES users (id),
+    to_user_id   uuid NOT NULL REFERENCES users (id),
+    score        bigint NOT NULL CHECK (score >= 1 AND score <= 5),
+    comment      text,
+    created_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_ratings_job_id ON ratings (job_id);
+CREATE INDEX IF NOT EXISTS idx_ratings_from_user_id ON ratings (from_user_id);
+CREATE INDEX IF NOT EXISTS idx_ratings_to_user_id ON ratings (to_user_id);
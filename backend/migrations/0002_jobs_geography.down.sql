@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_jobs_sync_location ON jobs;
+DROP FUNCTION IF EXISTS jobs_sync_location();
+DROP INDEX IF EXISTS idx_jobs_location_geo;
+ALTER TABLE jobs DROP COLUMN IF EXISTS location;
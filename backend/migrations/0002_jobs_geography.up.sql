@@ -0,0 +1,35 @@
+-- PostGIS is optional. Without it the geography column is not created and
+-- nearby searches fall back to a bounding box plus Haversine. To switch an
+-- existing database over after installing PostGIS, run
+-- `api migrate to 1` followed by `api migrate up`.
+
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS postgis;
+EXCEPTION WHEN OTHERS THEN
+    RAISE NOTICE 'PostGIS is not available (%), skipping geography column', SQLERRM;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis') THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE jobs ADD COLUMN IF NOT EXISTS location geography(Point, 4326);
+    UPDATE jobs SET location = ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography;
+    CREATE INDEX IF NOT EXISTS idx_jobs_location_geo ON jobs USING GIST (location);
+
+    -- Keep location in step with latitude/longitude on every write
+    CREATE OR REPLACE FUNCTION jobs_sync_location() RETURNS trigger AS $fn$
+    BEGIN
+        NEW.location := ST_SetSRID(ST_MakePoint(NEW.longitude, NEW.latitude), 4326)::geography;
+        RETURN NEW;
+    END;
+    $fn$ LANGUAGE plpgsql;
+
+    DROP TRIGGER IF EXISTS trg_jobs_sync_location ON jobs;
+    CREATE TRIGGER trg_jobs_sync_location
+        BEFORE INSERT OR UPDATE OF latitude, longitude ON jobs
+        FOR EACH ROW EXECUTE FUNCTION jobs_sync_location();
+END $$;
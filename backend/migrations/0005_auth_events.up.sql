@@ -0,0 +1,11 @@
+CREATE TABLE auth_events (
+    id         uuid PRIMARY KEY,
+    user_id    uuid REFERENCES users (id),
+    phone      varchar(20) NOT NULL,
+    type       varchar(30) NOT NULL,
+    ip         varchar(45),
+    user_agent varchar(255),
+    created_at timestamptz
+);
+CREATE INDEX idx_auth_events_user_id ON auth_events (user_id, created_at);
+CREATE INDEX idx_auth_events_phone ON auth_events (phone, created_at);
@@ -0,0 +1,25 @@
+CREATE TABLE sessions (
+    id           uuid PRIMARY KEY,
+    user_id      uuid NOT NULL REFERENCES users (id),
+    user_agent   varchar(255),
+    ip           varchar(45),
+    created_at   timestamptz,
+    last_used_at timestamptz NOT NULL,
+    expires_at   timestamptz NOT NULL,
+    revoked_at   timestamptz
+);
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+-- Every existing refresh token family becomes a session. The device of
+-- those logins was never recorded, so it stays empty.
+INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at)
+SELECT family_id,
+       min(user_id::text)::uuid,
+       '',
+       '',
+       min(created_at),
+       coalesce(max(created_at), now()),
+       max(expires_at),
+       CASE WHEN bool_and(revoked_at IS NOT NULL) THEN max(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id;
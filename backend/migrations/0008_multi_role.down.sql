@@ -0,0 +1,19 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS rating_avg   double precision DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count bigint DEFAULT 0;
+
+UPDATE users
+SET rating_avg = stats.avg, rating_count = stats.count
+FROM (SELECT to_user_id, AVG(score) AS avg, COUNT(*) AS count
+      FROM ratings GROUP BY to_user_id) stats
+WHERE stats.to_user_id = users.id;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS employer_rating_avg,
+    DROP COLUMN IF EXISTS employer_rating_count,
+    DROP COLUMN IF EXISTS worker_rating_avg,
+    DROP COLUMN IF EXISTS worker_rating_count,
+    DROP COLUMN IF EXISTS roles;
+
+DROP INDEX IF EXISTS idx_ratings_to_user_id_to_role;
+ALTER TABLE ratings DROP COLUMN IF EXISTS to_role;
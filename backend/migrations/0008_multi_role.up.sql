@@ -0,0 +1,36 @@
+-- Accounts hold a set of roles; role stays as the one currently active.
+ALTER TABLE users ADD COLUMN roles text[];
+UPDATE users SET roles = ARRAY[role];
+ALTER TABLE users ALTER COLUMN roles SET NOT NULL;
+
+-- Ratings remember which role the rated user had in the job
+ALTER TABLE ratings ADD COLUMN to_role varchar(20);
+UPDATE ratings
+SET to_role = CASE WHEN ratings.to_user_id = jobs.employer_id THEN 'employer' ELSE 'worker' END
+FROM jobs
+WHERE jobs.id = ratings.job_id;
+ALTER TABLE ratings ALTER COLUMN to_role SET NOT NULL;
+CREATE INDEX idx_ratings_to_user_id_to_role ON ratings (to_user_id, to_role);
+
+-- One rating aggregate per role replaces the combined one
+ALTER TABLE users
+    ADD COLUMN employer_rating_avg   double precision NOT NULL DEFAULT 0,
+    ADD COLUMN employer_rating_count bigint NOT NULL DEFAULT 0,
+    ADD COLUMN worker_rating_avg     double precision NOT NULL DEFAULT 0,
+    ADD COLUMN worker_rating_count   bigint NOT NULL DEFAULT 0;
+
+UPDATE users
+SET employer_rating_avg = stats.avg, employer_rating_count = stats.count
+FROM (SELECT to_user_id, AVG(score) AS avg, COUNT(*) AS count
+      FROM ratings WHERE to_role = 'employer' GROUP BY to_user_id) stats
+WHERE stats.to_user_id = users.id;
+
+UPDATE users
+SET worker_rating_avg = stats.avg, worker_rating_count = stats.count
+FROM (SELECT to_user_id, AVG(score) AS avg, COUNT(*) AS count
+      FROM ratings WHERE to_role = 'worker' GROUP BY to_user_id) stats
+WHERE stats.to_user_id = users.id;
+
+ALTER TABLE users
+    DROP COLUMN rating_avg,
+    DROP COLUMN rating_count;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS audit_logs;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS suspended_at,
+    DROP COLUMN IF EXISTS suspension_reason;
@@ -0,0 +1,17 @@
+ALTER TABLE users
+    ADD COLUMN suspended_at      timestamptz,
+    ADD COLUMN suspension_reason text;
+
+CREATE TABLE audit_logs (
+    id          uuid PRIMARY KEY,
+    admin_id    uuid REFERENCES users (id),
+    action      varchar(30) NOT NULL,
+    target_type varchar(20) NOT NULL,
+    target_id   uuid NOT NULL,
+    reason      text,
+    details     text,
+    ip          varchar(45),
+    created_at  timestamptz
+);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX idx_audit_logs_target ON audit_logs (target_type, target_id);
@@ -0,0 +1,8 @@
+UPDATE audit_logs SET action = 'user_unsuspended' WHERE action = 'user_reinstated';
+
+DROP INDEX IF EXISTS idx_users_status;
+ALTER TABLE users RENAME COLUMN status_reason TO suspension_reason;
+ALTER TABLE users RENAME COLUMN status_changed_at TO suspended_at;
+UPDATE users SET suspended_at = NULL, suspension_reason = NULL WHERE status = 'active';
+UPDATE users SET suspended_at = coalesce(suspended_at, now()) WHERE status <> 'active';
+ALTER TABLE users DROP COLUMN status;
@@ -0,0 +1,9 @@
+-- A status replaces the suspended flag, so accounts can also be banned or
+-- deleted. status_changed_at and status_reason describe the latest change.
+ALTER TABLE users ADD COLUMN status varchar(20) NOT NULL DEFAULT 'active';
+UPDATE users SET status = 'suspended' WHERE suspended_at IS NOT NULL;
+ALTER TABLE users RENAME COLUMN suspended_at TO status_changed_at;
+ALTER TABLE users RENAME COLUMN suspension_reason TO status_reason;
+CREATE INDEX idx_users_status ON users (status) WHERE status <> 'active';
+
+UPDATE audit_logs SET action = 'user_reinstated' WHERE action = 'user_unsuspended';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ratings_unrevealed;
+ALTER TABLE ratings DROP COLUMN IF EXISTS revealed_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS completed_at;
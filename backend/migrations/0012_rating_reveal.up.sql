@@ -0,0 +1,10 @@
+-- Ratings stay hidden until both participants have rated or the reveal
+-- window after completion has passed.
+ALTER TABLE jobs ADD COLUMN completed_at timestamptz;
+-- The completion time of earlier jobs is unknown; their window starts now
+UPDATE jobs SET completed_at = now() WHERE status = 'done';
+
+ALTER TABLE ratings ADD COLUMN revealed_at timestamptz;
+-- Ratings given so far were published at once
+UPDATE ratings SET revealed_at = created_at;
+CREATE INDEX idx_ratings_unrevealed ON ratings (job_id) WHERE revealed_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_completed_at;
+DROP TABLE IF EXISTS rating_reminders;
@@ -0,0 +1,10 @@
+-- One row per participant reminded to rate a job, so nobody is reminded
+-- twice for the same job.
+CREATE TABLE rating_reminders (
+    job_id  uuid NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    sent_at timestamptz NOT NULL,
+    PRIMARY KEY (job_id, user_id)
+);
+
+CREATE INDEX idx_jobs_completed_at ON jobs (completed_at) WHERE status = 'done';
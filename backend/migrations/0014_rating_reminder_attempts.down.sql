@@ -0,0 +1,6 @@
+DELETE FROM rating_reminders WHERE sent_at IS NULL;
+
+ALTER TABLE rating_reminders
+    DROP COLUMN last_attempt_at,
+    DROP COLUMN attempts,
+    ALTER COLUMN sent_at SET NOT NULL;
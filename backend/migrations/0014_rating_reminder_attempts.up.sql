@@ -0,0 +1,8 @@
+-- Failed reminder deliveries are recorded too, so they are retried with a
+-- backoff and given up on after a number of attempts.
+ALTER TABLE rating_reminders
+    ALTER COLUMN sent_at DROP NOT NULL,
+    ADD COLUMN attempts int NOT NULL DEFAULT 0,
+    ADD COLUMN last_attempt_at timestamptz;
+
+UPDATE rating_reminders SET attempts = 1, last_attempt_at = sent_at;
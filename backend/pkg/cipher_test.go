@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher("test key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plaintext := range []string{"", "JBSWY3DPEHPK3PXP", "ünïcødé"} {
+		sealed, err := c.Seal(plaintext)
+		if err != nil {
+			t.Fatalf("Seal(%q): %v", plaintext, err)
+		}
+		opened, err := c.Open(sealed)
+		if err != nil {
+			t.Fatalf("Open(Seal(%q)): %v", plaintext, err)
+		}
+		if opened != plaintext {
+			t.Errorf("Open(Seal(%q)) = %q", plaintext, opened)
+		}
+	}
+}
+
+func TestCipherSealUsesFreshNonces(t *testing.T) {
+	c, err := NewCipher("test key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := c.Seal("secret")
+	second, _ := c.Seal("secret")
+	if first == second {
+		t.Error("sealing the same plaintext twice gave the same ciphertext")
+	}
+}
+
+func TestCipherOpenRejects(t *testing.T) {
+	c, err := NewCipher("test key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewCipher("other key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := c.Seal("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		cipher *Cipher
+		sealed string
+	}{
+		{"wrong key", other, sealed},
+		{"tampered", c, base64.StdEncoding.EncodeToString(raw)},
+		{"not base64", c, "not base64!"},
+		{"too short", c, base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"empty", c, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.cipher.Open(tt.sealed); err == nil {
+				t.Error("Open succeeded")
+			}
+		})
+	}
+}
+
+func TestNewCipherEmptyKey(t *testing.T) {
+	if _, err := NewCipher(""); err == nil {
+		t.Error("NewCipher with an empty key succeeded")
+	}
+}
+
+func TestNewEphemeralCipher(t *testing.T) {
+	first, err := NewEphemeralCipher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewEphemeralCipher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := first.Seal("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened, err := first.Open(sealed); err != nil || opened != "secret" {
+		t.Errorf("Open = %q, %v", opened, err)
+	}
+	if _, err := second.Open(sealed); err == nil {
+		t.Error("a second ephemeral cipher opened the first one's ciphertext")
+	}
+}
@@ -8,79 +8,221 @@ import (
 	"github.com/google/uuid"
 )
 
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+	// TokenTypeMFA is the challenge token of a login that passed the
+	// password check and still needs a second factor.
+	TokenTypeMFA TokenType = "mfa"
+)
+
+// SigningKey is an HMAC key together with the key ID sent in the kid header.
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// TokenClaims are the claims of an access token. SessionID is the login
+// the token was issued to, so revoking the session can reject it.
 type TokenClaims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"sid"`
+	Role      string    `json:"role"`
+	Type      TokenType `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID uuid.UUID, role, secret string, expiry time.Duration) (string, error) {
-	claims := TokenClaims{
-		UserID: userID,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID.String(),
+// RefreshClaims identify a stored refresh token (ID) and the rotation
+// family it belongs to.
+type RefreshClaims struct {
+	FamilyID uuid.UUID `json:"fid"`
+	Type     TokenType `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the subject, which ValidateRefreshToken guarantees is a UUID.
+func (c *RefreshClaims) UserID() uuid.UUID {
+	id, _ := uuid.Parse(c.Subject)
+	return id
+}
+
+// MFAClaims identify the user a login challenge was issued to.
+type MFAClaims struct {
+	Type TokenType `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the subject, which ValidateMFAToken guarantees is a UUID.
+func (c *MFAClaims) UserID() uuid.UUID {
+	id, _ := uuid.Parse(c.Subject)
+	return id
+}
+
+// TokenIssuer signs and validates the tokens issued by this API. Access
+// tokens are signed with the asymmetric keys of a Keyring so other services
+// can verify them from the JWKS; refresh and MFA challenge tokens are only
+// ever read by this API and use HMAC keys. A token is only accepted as the type it was
+// issued as: the token_type claim, the audience, the issuer and the kid
+// header must all match.
+type TokenIssuer struct {
+	issuer     string
+	accessKeys *Keyring
+	hmacKeys   map[TokenType]SigningKey
+}
+
+func NewTokenIssuer(issuer string, accessKeys *Keyring, refreshKey, mfaKey SigningKey) *TokenIssuer {
+	return &TokenIssuer{
+		issuer:     issuer,
+		accessKeys: accessKeys,
+		hmacKeys: map[TokenType]SigningKey{
+			TokenTypeRefresh: refreshKey,
+			TokenTypeMFA:     mfaKey,
 		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+// JWKS returns the public keys that verify access tokens.
+func (i *TokenIssuer) JWKS() JWKSet {
+	return i.accessKeys.JWKS()
 }
 
-func GenerateRefreshToken(userID uuid.UUID, secret string, expiry time.Duration) (string, error) {
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   userID.String(),
+func (i *TokenIssuer) GenerateAccessToken(userID, sessionID uuid.UUID, role string, expiry time.Duration) (string, error) {
+	claims := TokenClaims{
+		UserID:           userID,
+		SessionID:        sessionID,
+		Role:             role,
+		Type:             TokenTypeAccess,
+		RegisteredClaims: i.registeredClaims(TokenTypeAccess, userID, uuid.New(), expiry),
 	}
+	return i.sign(TokenTypeAccess, claims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+func (i *TokenIssuer) GenerateRefreshToken(userID, tokenID, familyID uuid.UUID, expiry time.Duration) (string, error) {
+	claims := RefreshClaims{
+		FamilyID:         familyID,
+		Type:             TokenTypeRefresh,
+		RegisteredClaims: i.registeredClaims(TokenTypeRefresh, userID, tokenID, expiry),
+	}
+	return i.sign(TokenTypeRefresh, claims)
 }
 
-func ValidateToken(tokenString, secret string) (*TokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+func (i *TokenIssuer) GenerateMFAToken(userID uuid.UUID, expiry time.Duration) (string, error) {
+	claims := MFAClaims{
+		Type:             TokenTypeMFA,
+		RegisteredClaims: i.registeredClaims(TokenTypeMFA, userID, uuid.New(), expiry),
+	}
+	return i.sign(TokenTypeMFA, claims)
+}
 
-	if err != nil {
+func (i *TokenIssuer) ValidateAccessToken(tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	if err := i.parse(TokenTypeAccess, tokenString, claims); err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*TokenClaims)
-	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+	if claims.Type != TokenTypeAccess || claims.UserID == uuid.Nil {
+		return nil, errors.New("not an access token")
 	}
 
 	return claims, nil
 }
 
-func ValidateRefreshToken(tokenString, secret string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+func (i *TokenIssuer) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := i.parse(TokenTypeRefresh, tokenString, claims); err != nil {
+		return nil, err
+	}
 
-	if err != nil {
-		return uuid.Nil, err
+	if claims.Type != TokenTypeRefresh {
+		return nil, errors.New("not a refresh token")
+	}
+	if _, err := uuid.Parse(claims.Subject); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (i *TokenIssuer) ValidateMFAToken(tokenString string) (*MFAClaims, error) {
+	claims := &MFAClaims{}
+	if err := i.parse(TokenTypeMFA, tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.Type != TokenTypeMFA {
+		return nil, errors.New("not an MFA token")
+	}
+	if _, err := uuid.Parse(claims.Subject); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (i *TokenIssuer) registeredClaims(typ TokenType, userID, tokenID uuid.UUID, expiry time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        tokenID.String(),
+		Issuer:    i.issuer,
+		Audience:  jwt.ClaimStrings{string(typ)},
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Subject:   userID.String(),
 	}
+}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("invalid token")
+func (i *TokenIssuer) sign(typ TokenType, claims jwt.Claims) (string, error) {
+	if typ == TokenTypeAccess {
+		key := i.accessKeys.signingKey()
+		token := jwt.NewWithClaims(key.method, claims)
+		token.Header["kid"] = key.id
+		return token.SignedString(key.private)
 	}
 
-	userID, err := uuid.Parse(claims.Subject)
+	key := i.hmacKeys[typ]
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Secret)
+}
+
+func (i *TokenIssuer) parse(typ TokenType, tokenString string, claims jwt.Claims) error {
+	methods := []string{jwt.SigningMethodHS256.Alg()}
+	if typ == TokenTypeAccess {
+		methods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if typ != TokenTypeAccess {
+			key := i.hmacKeys[typ]
+			if kid != key.ID {
+				return nil, errors.New("unknown signing key")
+			}
+			return key.Secret, nil
+		}
+
+		key, ok := i.accessKeys.verificationKey(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("signing method does not match key")
+		}
+		return key.private.Public(), nil
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(i.issuer),
+		jwt.WithAudience(string(typ)),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
-		return uuid.Nil, err
+		return err
 	}
 
-	return userID, nil
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
 }
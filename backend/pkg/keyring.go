@@ -0,0 +1,301 @@
+package pkg
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyringKey is one asymmetric key of a Keyring. Its ID is the file name
+// without the .pem extension.
+type keyringKey struct {
+	id         string
+	method     jwt.SigningMethod
+	private    crypto.Signer
+	activeFrom time.Time
+}
+
+// Keyring holds the private keys that sign access tokens, loaded from *.pem
+// files in a directory. Every key in the directory verifies tokens and is
+// published in the JWKS. The newest key that has been in the directory for
+// at least the activation delay signs new tokens, which lets verifiers pick
+// a new key up from the JWKS before tokens signed with it appear.
+//
+// To rotate, add a new key file, wait for the activation delay, and remove
+// the old file once the tokens it signed have expired.
+type Keyring struct {
+	dir             string
+	activationDelay time.Duration
+
+	mu      sync.RWMutex
+	keys    map[string]*keyringKey
+	signing *keyringKey
+}
+
+// LoadKeyring reads every key in dir. It fails when dir holds no keys.
+func LoadKeyring(dir string, activationDelay time.Duration) (*Keyring, error) {
+	k := &Keyring{dir: dir, activationDelay: activationDelay}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// NewEphemeralKeyring returns a keyring with a single in-memory Ed25519 key,
+// for development without a key directory. Tokens it signs do not survive a
+// restart and are not accepted by other replicas.
+func NewEphemeralKeyring() (*Keyring, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key := &keyringKey{
+		id:      "ephemeral-" + time.Now().UTC().Format("20060102T150405"),
+		method:  jwt.SigningMethodEdDSA,
+		private: private,
+	}
+	return &Keyring{keys: map[string]*keyringKey{key.id: key}, signing: key}, nil
+}
+
+// Reload rereads the key directory. On error the previous keys stay in use.
+func (k *Keyring) Reload() error {
+	if k.dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no *.pem keys found in %s", k.dir)
+	}
+
+	keys := make(map[string]*keyringKey, len(paths))
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		keys[key.id] = key
+	}
+
+	signing := pickSigningKey(keys, time.Now().Add(-k.activationDelay))
+
+	k.mu.Lock()
+	k.keys = keys
+	k.signing = signing
+	k.mu.Unlock()
+	return nil
+}
+
+// pickSigningKey returns the newest key active since before cutoff, or the
+// oldest key when none is old enough yet, such as on a fresh install.
+func pickSigningKey(keys map[string]*keyringKey, cutoff time.Time) *keyringKey {
+	sorted := make([]*keyringKey, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].activeFrom.Equal(sorted[j].activeFrom) {
+			return sorted[i].id < sorted[j].id
+		}
+		return sorted[i].activeFrom.Before(sorted[j].activeFrom)
+	})
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if !sorted[i].activeFrom.After(cutoff) {
+			return sorted[i]
+		}
+	}
+	return sorted[0]
+}
+
+func loadKey(path string) (*keyringKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &keyringKey{
+		id:         strings.TrimSuffix(filepath.Base(path), ".pem"),
+		activeFrom: info.ModTime(),
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.method = jwt.SigningMethodRS256
+		key.private = private
+	case ed25519.PrivateKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.private = private
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return key, nil
+}
+
+func (k *Keyring) signingKey() *keyringKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.signing
+}
+
+func (k *Keyring) verificationKey(id string) (*keyringKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// SigningKeyID returns the ID of the key currently signing new tokens.
+func (k *Keyring) SigningKeyID() string {
+	return k.signingKey().id
+}
+
+// JWK is the public half of a keyring key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of every key in the ring.
+func (k *Keyring) JWKS() JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+		switch public := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// GenerateKeyFile writes a new private key to dir in PKCS #8 PEM form and
+// returns its path. alg is "EdDSA" or "RS256".
+func GenerateKeyFile(dir, alg string) (string, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 3072)
+	default:
+		return "", fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, time.Now().UTC().Format("20060102T150405")+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Watch reloads the key directory every interval until ctx is cancelled, so
+// added and removed key files take effect without a restart and a new key
+// starts signing once its activation delay has passed.
+func (k *Keyring) Watch(ctx context.Context, interval time.Duration) {
+	if k.dir == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			previous := k.SigningKeyID()
+			if err := k.Reload(); err != nil {
+				log.Printf("Keyring: reload failed, keeping current keys: %v", err)
+				continue
+			}
+			if current := k.SigningKeyID(); current != previous {
+				log.Printf("Keyring: now signing access tokens with key %s", current)
+			}
+		}
+	}
+}
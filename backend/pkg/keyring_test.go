@@ -0,0 +1,228 @@
+package pkg
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPickSigningKey(t *testing.T) {
+	now := time.Now()
+	key := func(id string, age time.Duration) *keyringKey {
+		return &keyringKey{id: id, activeFrom: now.Add(-age)}
+	}
+	cutoff := now.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		keys []*keyringKey
+		want string
+	}{
+		{"single active key", []*keyringKey{key("a", 2*time.Hour)}, "a"},
+		{"single new key", []*keyringKey{key("a", time.Minute)}, "a"},
+		{"newest active key", []*keyringKey{key("a", 48*time.Hour), key("b", 2*time.Hour)}, "b"},
+		{"new key not yet active", []*keyringKey{key("a", 48*time.Hour), key("b", time.Minute)}, "a"},
+		{"key active exactly at cutoff", []*keyringKey{key("a", 48*time.Hour), key("b", time.Hour)}, "b"},
+		{"no key active yet", []*keyringKey{key("b", time.Minute), key("a", 2*time.Minute)}, "a"},
+		{"same age ordered by ID", []*keyringKey{key("b", 2*time.Hour), key("a", 2*time.Hour)}, "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := make(map[string]*keyringKey, len(tt.keys))
+			for _, k := range tt.keys {
+				keys[k.id] = k
+			}
+			if got := pickSigningKey(keys, cutoff); got.id != tt.want {
+				t.Errorf("pickSigningKey = %q, want %q", got.id, tt.want)
+			}
+		})
+	}
+}
+
+// writeKey writes a PEM block to dir/id.pem, last modified age ago.
+func writeKey(t *testing.T, dir, id string, block *pem.Block, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, id+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func pkcs8Block(t *testing.T, key interface{}) *pem.Block {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeKey(t, dir, "old", pkcs8Block(t, edKey), 48*time.Hour)
+	writeKey(t, dir, "current", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, 2*time.Hour)
+	writeKey(t, dir, "next", pkcs8Block(t, edKey), time.Minute)
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := LoadKeyring(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := k.SigningKeyID(); got != "current" {
+		t.Errorf("SigningKeyID = %q, want %q", got, "current")
+	}
+
+	jwks := k.JWKS()
+	want := []struct{ id, kty, alg string }{
+		{"current", "RSA", "RS256"},
+		{"next", "OKP", "EdDSA"},
+		{"old", "OKP", "EdDSA"},
+	}
+	if len(jwks.Keys) != len(want) {
+		t.Fatalf("JWKS has %d keys, want %d", len(jwks.Keys), len(want))
+	}
+	for i, w := range want {
+		got := jwks.Keys[i]
+		if got.KeyID != w.id || got.KeyType != w.kty || got.Algorithm != w.alg {
+			t.Errorf("JWKS key %d = %s/%s/%s, want %s/%s/%s", i, got.KeyID, got.KeyType, got.Algorithm, w.id, w.kty, w.alg)
+		}
+	}
+
+	// Once the new key has been in the directory long enough, it signs
+	at := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "next.pem"), at, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "old.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := k.SigningKeyID(); got != "next" {
+		t.Errorf("SigningKeyID after reload = %q, want %q", got, "next")
+	}
+	if _, ok := k.verificationKey("old"); ok {
+		t.Error("removed key still verifies tokens")
+	}
+}
+
+func TestLoadKeyringRejects(t *testing.T) {
+	smallRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		block *pem.Block
+	}{
+		{"RSA key under 2048 bits", pkcs8Block(t, smallRSA)},
+		{"unsupported key type", pkcs8Block(t, ecKey)},
+		{"unsupported PEM block", &pem.Block{Type: "CERTIFICATE", Bytes: []byte("x")}},
+		{"malformed key", &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("x")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeKey(t, dir, "key", tt.block, time.Hour)
+			if _, err := LoadKeyring(dir, time.Hour); err == nil {
+				t.Error("LoadKeyring succeeded")
+			}
+		})
+	}
+
+	t.Run("no PEM block", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "key.pem"), []byte("not PEM"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadKeyring(dir, time.Hour); err == nil {
+			t.Error("LoadKeyring succeeded")
+		}
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		if _, err := LoadKeyring(t.TempDir(), time.Hour); err == nil {
+			t.Error("LoadKeyring succeeded")
+		}
+	})
+}
+
+func TestKeyringReloadKeepsKeysOnError(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeKey(t, dir, "good", pkcs8Block(t, edKey), time.Hour)
+
+	k, err := LoadKeyring(dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("not PEM"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Reload(); err == nil {
+		t.Fatal("Reload with a bad key file succeeded")
+	}
+	if got := k.SigningKeyID(); got != "good" {
+		t.Errorf("SigningKeyID = %q, want %q", got, "good")
+	}
+}
+
+func TestGenerateKeyFile(t *testing.T) {
+	for _, alg := range []string{"EdDSA", "RS256"} {
+		t.Run(alg, func(t *testing.T) {
+			dir := t.TempDir()
+			path, err := GenerateKeyFile(dir, alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			k, err := LoadKeyring(dir, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := k.SigningKeyID()+".pem", filepath.Base(path); got != want {
+				t.Errorf("signing key file = %q, want %q", got, want)
+			}
+			if got := k.JWKS().Keys[0].Algorithm; got != alg {
+				t.Errorf("algorithm = %q, want %q", got, alg)
+			}
+		})
+	}
+
+	if _, err := GenerateKeyFile(t.TempDir(), "HS256"); err == nil {
+		t.Error("GenerateKeyFile with HS256 succeeded")
+	}
+}
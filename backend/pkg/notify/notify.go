@@ -0,0 +1,50 @@
+// Package notify delivers notifications to users. Notifications go out by
+// SMS or to the application log; a push or email provider implements
+// Notifier.
+package notify
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/work-near-me/backend/pkg/sms"
+)
+
+// Notification is a message for one user. Phone is the user's number, for
+// notifiers that deliver by SMS.
+type Notification struct {
+	UserID  uuid.UUID
+	Phone   string
+	Message string
+}
+
+// Notifier delivers a notification to its user.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// LogNotifier writes notifications to the application log instead of
+// delivering them.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(notification Notification) error {
+	log.Printf("Notification to %s: %s", notification.UserID, notification.Message)
+	return nil
+}
+
+// SMSNotifier texts notifications to the user's phone number.
+type SMSNotifier struct {
+	sender sms.Sender
+}
+
+func NewSMSNotifier(sender sms.Sender) *SMSNotifier {
+	return &SMSNotifier{sender: sender}
+}
+
+func (n *SMSNotifier) Notify(notification Notification) error {
+	return n.sender.Send(notification.Phone, notification.Message)
+}
@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPhone is returned for input that is not a mobile phone number.
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// vnCountryCode is assumed for numbers written without a country code.
+const vnCountryCode = "84"
+
+// vnMobilePrefixes are the first two digits of Vietnamese mobile numbers
+// (after the leading 0) in use by the carriers.
+var vnMobilePrefixes = map[string]bool{
+	// Viettel
+	"32": true, "33": true, "34": true, "35": true, "36": true, "37": true, "38": true, "39": true,
+	"86": true, "96": true, "97": true, "98": true,
+	// Vinaphone
+	"81": true, "82": true, "83": true, "84": true, "85": true, "88": true, "91": true, "94": true,
+	// Mobifone
+	"70": true, "76": true, "77": true, "78": true, "79": true, "89": true, "90": true, "93": true,
+	// Vietnamobile
+	"52": true, "56": true, "58": true, "92": true,
+	// Gmobile
+	"59": true, "99": true,
+	// Reddi, Itelecom
+	"55": true, "87": true,
+}
+
+// NormalizePhone returns phone in E.164 form, e.g. "+84912345678". It
+// accepts the usual ways of writing a number: with or without spaces, dots,
+// dashes and parentheses, with a leading 0 for Vietnamese numbers, and with
+// a +, 00 or bare country code. Vietnamese numbers must be mobile numbers
+// of a known carrier; other countries are only checked for length.
+func NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			digits.WriteString("+")
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	number := digits.String()
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = vnCountryCode + number[1:]
+	case strings.HasPrefix(number, vnCountryCode) && len(number) == len(vnCountryCode)+9:
+		// Already has the country code, just no +
+	case len(number) == 9:
+		// National number with the leading 0 dropped
+		number = vnCountryCode + number
+	}
+
+	if strings.HasPrefix(number, vnCountryCode) {
+		national := strings.TrimPrefix(number[len(vnCountryCode):], "0")
+		if len(national) != 9 || !vnMobilePrefixes[national[:2]] {
+			return "", ErrInvalidPhone
+		}
+		return "+" + vnCountryCode + national, nil
+	}
+
+	// E.164 allows at most 15 digits and country codes never start with 0
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+	return "+" + number, nil
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		err   error
+	}{
+		{"national with leading zero", "0912345678", "+84912345678", nil},
+		{"E.164", "+84912345678", "+84912345678", nil},
+		{"country code without plus", "84912345678", "+84912345678", nil},
+		{"international 00 prefix", "0084912345678", "+84912345678", nil},
+		{"leading zero dropped", "912345678", "+84912345678", nil},
+		{"country code with leading zero", "+84 0912 345 678", "+84912345678", nil},
+		{"spaces", " +84 91 234 56 78 ", "+84912345678", nil},
+		{"dots and dashes", "091.234-5678", "+84912345678", nil},
+		{"parentheses", "(091) 234 5678", "+84912345678", nil},
+		{"other country", "+1 (415) 555-2671", "+14155552671", nil},
+		{"other country with 00", "0044 20 7946 0958", "+442079460958", nil},
+		{"landline", "0241234567", "", ErrInvalidPhone},
+		{"unknown mobile prefix", "0112345678", "", ErrInvalidPhone},
+		{"too short", "091234567", "", ErrInvalidPhone},
+		{"too long", "09123456789", "", ErrInvalidPhone},
+		{"other country too short", "+1415555", "", ErrInvalidPhone},
+		{"other country too long", "+1234567890123456", "", ErrInvalidPhone},
+		{"country code starting with zero", "+0123456789", "", ErrInvalidPhone},
+		{"plus in the middle", "0912+345678", "", ErrInvalidPhone},
+		{"letters", "09123abc78", "", ErrInvalidPhone},
+		{"empty", "", "", ErrInvalidPhone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhone(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NormalizePhone(%q) error = %v, want %v", tt.input, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhone(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package sms sends text messages to phone numbers. Only development
+// stand-ins are provided; a real provider implements Sender.
+package sms
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sender delivers a text message to a phone number.
+type Sender interface {
+	Send(phone, message string) error
+}
+
+// LogSender writes messages to the application log instead of sending them.
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(phone, message string) error {
+	log.Printf("SMS to %s: %s", phone, message)
+	return nil
+}
+
+// FileSender appends messages to a file, one per line, so tests and local
+// tooling can read the codes that would have been sent.
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(phone, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().UTC().Format(time.RFC3339), phone, message)
+	return err
+}
@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed from RFC 6238 appendix B, in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// The RFC lists 8-digit codes; these are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode with an invalid secret succeeded")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := TOTPStep(now)
+	code := func(step int64) string {
+		c, err := TOTPCode(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfcSecret, code(step), 0, step, true},
+		{"previous step", rfcSecret, code(step - 1), 0, step - 1, true},
+		{"next step", rfcSecret, code(step + 1), 0, step + 1, true},
+		{"two steps behind", rfcSecret, code(step - 2), 0, 0, false},
+		{"two steps ahead", rfcSecret, code(step + 2), 0, 0, false},
+		{"surrounding spaces", rfcSecret, " " + code(step) + " ", 0, step, true},
+		{"lowercase secret", strings.ToLower(rfcSecret), code(step), 0, step, true},
+		{"already used", rfcSecret, code(step), step, 0, false},
+		{"older than last used", rfcSecret, code(step - 1), step - 1, 0, false},
+		{"newer than last used", rfcSecret, code(step), step - 1, step, true},
+		{"wrong code", rfcSecret, "000000", 0, 0, false},
+		{"too short", rfcSecret, code(step)[:5], 0, 0, false},
+		{"too long", rfcSecret, code(step) + "0", 0, 0, false},
+		{"invalid secret", "not base32!", code(step), 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := ValidateTOTP(tt.secret, tt.code, now, tt.lastStep)
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret %q has %d characters, want 32", secret, len(secret))
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("generated secret cannot be used: %v", err)
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"abcde-fghij", "abcde-fghij"},
+		{"abcdefghij", "abcde-fghij"},
+		{"ABCDE-FGHIJ", "abcde-fghij"},
+		{" abcde fghij ", "abcde-fghij"},
+		{"ab-cde-fgh-ij", "abcde-fghij"},
+		{"abcde", "abcde"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.input); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if NormalizeRecoveryCode(code) != code {
+		t.Errorf("generated code %q is not in normalized form", code)
+	}
+}
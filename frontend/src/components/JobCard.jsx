@@ -76,7 +76,7 @@ export default function JobCard({ job, showDistance = false }) {
                             <Star sx={{ fontSize: 18, color: '#FFB84D' }} />
                             <Typography variant="body2" color="text.secondary">
                                 {job.employer.name}
-                                {job.employer.rating_avg > 0 && ` · ${job.employer.rating_avg.toFixed(1)}★`}
+                                {job.employer.employer_rating_avg > 0 && ` · ${job.employer.employer_rating_avg.toFixed(1)}★`}
                             </Typography>
                         </Box>
                     )}
@@ -86,7 +86,7 @@ export default function JobCard({ job, showDistance = false }) {
                             <Person sx={{ fontSize: 18, color: 'primary.main' }} />
                             <Typography variant="body2" color="text.secondary">
                                 Worker: <strong>{job.assigned_worker.name}</strong>
-                                {job.assigned_worker.rating_avg > 0 && ` · ${job.assigned_worker.rating_avg.toFixed(1)}★`}
+                                {job.assigned_worker.worker_rating_avg > 0 && ` · ${job.assigned_worker.worker_rating_avg.toFixed(1)}★`}
                             </Typography>
                         </Box>
                     )}
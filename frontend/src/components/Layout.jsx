@@ -32,7 +32,7 @@ import { useAuth } from '../context/AuthContext';
 
 export default function Layout({ children }) {
     const [drawerOpen, setDrawerOpen] = useState(false);
-    const { user, isAuthenticated, isEmployer, logout } = useAuth();
+    const { user, isAuthenticated, isEmployer, switchRole, logout } = useAuth();
     const navigate = useNavigate();
     const location = useLocation();
     const theme = useTheme();
@@ -62,17 +62,23 @@ export default function Layout({ children }) {
                         {user.name?.[0]?.toUpperCase()}
                     </Avatar>
                     <Typography variant="subtitle1" fontWeight={600}>{user.name}</Typography>
-                    <Chip
-                        label={user.role}
-                        size="small"
-                        color={user.role === 'employer' ? 'primary' : 'secondary'}
-                        sx={{ mt: 0.5 }}
-                    />
-                    {user.rating_avg > 0 && (
+                    <Box sx={{ display: 'flex', justifyContent: 'center', gap: 0.5, mt: 0.5 }}>
+                        {(user.roles || [user.role]).map((role) => (
+                            <Chip
+                                key={role}
+                                label={role}
+                                size="small"
+                                color={role === 'employer' ? 'primary' : 'secondary'}
+                                variant={role === user.role ? 'filled' : 'outlined'}
+                                onClick={role === user.role ? undefined : () => switchRole(role)}
+                            />
+                        ))}
+                    </Box>
+                    {user[`${user.role}_rating_avg`] > 0 && (
                         <Box sx={{ display: 'flex', alignItems: 'center', justifyContent: 'center', mt: 1, gap: 0.5 }}>
                             <StarIcon sx={{ fontSize: 16, color: '#FFB84D' }} />
                             <Typography variant="body2" color="text.secondary">
-                                {user.rating_avg.toFixed(1)} ({user.rating_count})
+                                {user[`${user.role}_rating_avg`].toFixed(1)} ({user[`${user.role}_rating_count`]})
                             </Typography>
                         </Box>
                     )}
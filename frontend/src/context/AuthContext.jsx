@@ -16,9 +16,8 @@ export function AuthProvider({ children }) {
         setLoading(false);
     }, []);
 
-    const login = useCallback(async (phone, password) => {
-        const response = await api.post('/auth/login', { phone, password });
-        const { access_token, refresh_token, user: userData } = response.data;
+    const startSession = useCallback((data) => {
+        const { access_token, refresh_token, user: userData } = data;
         localStorage.setItem('access_token', access_token);
         localStorage.setItem('refresh_token', refresh_token);
         localStorage.setItem('user', JSON.stringify(userData));
@@ -26,6 +25,21 @@ export function AuthProvider({ children }) {
         return userData;
     }, []);
 
+    // Resolves to { mfaToken } instead of the user when the account has
+    // two-factor authentication; finish with loginMFA.
+    const login = useCallback(async (phone, password) => {
+        const response = await api.post('/auth/login', { phone, password });
+        if (response.data.mfa_required) {
+            return { mfaToken: response.data.mfa_token };
+        }
+        return startSession(response.data);
+    }, [startSession]);
+
+    const loginMFA = useCallback(async (mfaToken, code) => {
+        const response = await api.post('/auth/login/mfa', { mfa_token: mfaToken, code });
+        return startSession(response.data);
+    }, [startSession]);
+
     const register = useCallback(async (name, phone, password, role) => {
         const response = await api.post('/auth/register', { name, phone, password, role });
         const { access_token, refresh_token, user: userData } = response.data;
@@ -36,7 +50,18 @@ export function AuthProvider({ children }) {
         return userData;
     }, []);
 
+    // Makes role the active one; the response carries tokens for it.
+    const switchRole = useCallback(async (role) => {
+        const response = await api.put('/auth/role', { role });
+        return startSession(response.data);
+    }, [startSession]);
+
     const logout = useCallback(() => {
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (refreshToken) {
+            // Revoke the session server-side; local state is cleared regardless
+            api.post('/auth/logout', { refresh_token: refreshToken }).catch(() => {});
+        }
         localStorage.removeItem('access_token');
         localStorage.removeItem('refresh_token');
         localStorage.removeItem('user');
@@ -47,7 +72,9 @@ export function AuthProvider({ children }) {
         user,
         loading,
         login,
+        loginMFA,
         register,
+        switchRole,
         logout,
         isAuthenticated: !!user,
         isEmployer: user?.role === 'employer',
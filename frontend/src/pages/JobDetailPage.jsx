@@ -79,7 +79,7 @@ export default function JobDetailPage() {
     const handleRatingClose = (submitted) => {
         setRatingOpen(false);
         if (submitted) {
-            setSuccess('Rating submitted successfully!');
+            setSuccess('Rating submitted! It will be shown once both of you have rated, or when the reveal period after the job ends.');
         }
     };
 
@@ -101,6 +101,7 @@ export default function JobDetailPage() {
 
     const isOwner = user && job.employer_id === user.id;
     const isAssignedWorker = user && job.assigned_worker_id === user.id;
+    const ratingClosed = Boolean(job.rating_deadline) && new Date(job.rating_deadline) < new Date();
 
     return (
         <Container maxWidth="md" sx={{ mt: 3 }}>
@@ -151,8 +152,8 @@ export default function JobDetailPage() {
                             <Person sx={{ color: 'text.secondary' }} />
                             <Typography color="text.secondary">
                                 Posted by <strong>{job.employer.name}</strong>
-                                {job.employer.rating_avg > 0 && (
-                                    <> · <Star sx={{ fontSize: 14, verticalAlign: 'middle', color: '#FFB84D' }} /> {job.employer.rating_avg.toFixed(1)}</>
+                                {job.employer.employer_rating_avg > 0 && (
+                                    <> · <Star sx={{ fontSize: 14, verticalAlign: 'middle', color: '#FFB84D' }} /> {job.employer.employer_rating_avg.toFixed(1)}</>
                                 )}
                             </Typography>
                         </Box>
@@ -225,7 +226,7 @@ export default function JobDetailPage() {
                             onClick={() => !job.employer_rated && handleOpenRating(job.assigned_worker_id, 'Worker')}
                             startIcon={job.employer_rated ? <CheckCircle /> : <Star />}
                             fullWidth
-                            disabled={job.employer_rated}
+                            disabled={job.employer_rated || ratingClosed}
                         >
                             {job.employer_rated ? 'Worker Rated' : 'Rate Worker'}
                         </Button>
@@ -238,11 +239,19 @@ export default function JobDetailPage() {
                             onClick={() => !job.worker_rated && handleOpenRating(job.employer_id, job.employer?.name)}
                             startIcon={job.worker_rated ? <CheckCircle /> : <Star />}
                             fullWidth
-                            disabled={job.worker_rated}
+                            disabled={job.worker_rated || ratingClosed}
                         >
                             {job.worker_rated ? 'Employer Rated' : 'Rate Employer'}
                         </Button>
                     )}
+
+                    {job.status === 'done' && job.rating_deadline && (isOwner || isAssignedWorker) && (
+                        <Typography variant="body2" color="text.secondary" textAlign="center">
+                            {ratingClosed
+                                ? 'The rating period for this job has ended'
+                                : `Ratings close ${new Date(job.rating_deadline).toLocaleString('vi-VN')}`}
+                        </Typography>
+                    )}
                 </Stack>
             </Paper>
 
@@ -11,9 +11,11 @@ export default function LoginPage() {
     const [phone, setPhone] = useState('');
     const [password, setPassword] = useState('');
     const [showPassword, setShowPassword] = useState(false);
+    const [mfaToken, setMfaToken] = useState('');
+    const [code, setCode] = useState('');
     const [error, setError] = useState('');
     const [loading, setLoading] = useState(false);
-    const { login } = useAuth();
+    const { login, loginMFA } = useAuth();
     const navigate = useNavigate();
 
     const handleSubmit = async (e) => {
@@ -22,7 +24,15 @@ export default function LoginPage() {
         setLoading(true);
 
         try {
-            await login(phone, password);
+            if (mfaToken) {
+                await loginMFA(mfaToken, code);
+            } else {
+                const result = await login(phone, password);
+                if (result.mfaToken) {
+                    setMfaToken(result.mfaToken);
+                    return;
+                }
+            }
             navigate('/jobs');
         } catch (err) {
             setError(err.response?.data?.error || 'Login failed');
@@ -56,6 +66,19 @@ export default function LoginPage() {
                     {error && <Alert severity="error" sx={{ mb: 2 }}>{error}</Alert>}
 
                     <Box component="form" onSubmit={handleSubmit}>
+                        {mfaToken ? (
+                        <TextField
+                            fullWidth
+                            label="Authentication or recovery code"
+                            value={code}
+                            onChange={(e) => setCode(e.target.value)}
+                            margin="normal"
+                            required
+                            autoFocus
+                            autoComplete="one-time-code"
+                        />
+                        ) : (
+                        <>
                         <TextField
                             fullWidth
                             label="Phone Number"
@@ -84,6 +107,8 @@ export default function LoginPage() {
                                 ),
                             }}
                         />
+                        </>
+                        )}
 
                         <Button
                             type="submit"
@@ -93,7 +118,7 @@ export default function LoginPage() {
                             disabled={loading}
                             sx={{ mt: 3, mb: 2 }}
                         >
-                            {loading ? 'Signing in...' : 'Sign In'}
+                            {loading ? 'Signing in...' : mfaToken ? 'Verify' : 'Sign In'}
                         </Button>
 
                         <Typography textAlign="center" color="text.secondary">
@@ -19,7 +19,7 @@ export default function NearbyJobsPage() {
         setError('');
         try {
             const response = await api.get('/jobs/nearby', {
-                params: { lat, lng, radius: r },
+                params: { lat, lng, radius: r, limit: 50 },
             });
             setJobs(response.data.jobs || []);
         } catch (err) {
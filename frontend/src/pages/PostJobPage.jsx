@@ -40,6 +40,8 @@ export default function PostJobPage() {
         description: '',
         hourly_rate: '',
         total_payment: '',
+        start_time: '',
+        end_time: '',
     });
     const [position, setPosition] = useState(null);
     const [error, setError] = useState('');
@@ -147,6 +149,29 @@ export default function PostJobPage() {
                         />
                     </Box>
 
+                    <Box sx={{ display: 'flex', gap: 2 }}>
+                        <TextField
+                            fullWidth
+                            label="Start Time"
+                            value={formData.start_time}
+                            onChange={handleChange('start_time')}
+                            required
+                            type="datetime-local"
+                            margin="normal"
+                            InputLabelProps={{ shrink: true }}
+                        />
+                        <TextField
+                            fullWidth
+                            label="End Time"
+                            value={formData.end_time}
+                            onChange={handleChange('end_time')}
+                            required
+                            type="datetime-local"
+                            margin="normal"
+                            InputLabelProps={{ shrink: true }}
+                        />
+                    </Box>
+
                     <Box sx={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', mt: 3, mb: 1 }}>
                         <Typography variant="subtitle1" fontWeight={600}>
                             📍 Job Location (click on map)